@@ -9,3 +9,155 @@ const (
 	// IroncoreMetalClusterReady documents the status of IroncoreMetalCluster and its underlying resources.
 	IroncoreMetalClusterReady clusterv1.ConditionType = "ClusterReady"
 )
+
+const (
+	// IgnitionReadyCondition documents the status of the ignition rendered for an IroncoreMetalMachine.
+	IgnitionReadyCondition clusterv1.ConditionType = "IgnitionReady"
+
+	// IgnitionSizeNearLimitReason (Severity=Warning) documents an ignition whose size approaches the Secret size limit.
+	IgnitionSizeNearLimitReason = "IgnitionSizeNearLimit"
+	// IgnitionTooLargeReason (Severity=Error) documents an ignition which does not fit into Secrets, even when split.
+	IgnitionTooLargeReason = "IgnitionTooLarge"
+	// InvalidBootstrapDataReason (Severity=Error) documents bootstrap data which does not render into a valid ignition.
+	// It is also used as terminal FailureReason of the IroncoreMetalMachine.
+	InvalidBootstrapDataReason = "InvalidBootstrapData"
+	// UnsupportedBootstrapFormatReason (Severity=Error) documents bootstrap data in a format which can neither be
+	// converted into an ignition nor passed through. It is also used as terminal FailureReason of the IroncoreMetalMachine.
+	UnsupportedBootstrapFormatReason = "UnsupportedBootstrapFormat"
+	// InvalidTrustedCABundleReason (Severity=Error) documents a trusted CA bundle of the IroncoreMetalCluster which
+	// contains no PEM encoded certificate. The ignition is rendered once the bundle is corrected.
+	InvalidTrustedCABundleReason = "InvalidTrustedCABundle"
+)
+
+const (
+	// RAIDLayoutUnsupportedCondition is set to True with the RAIDLayoutUnsupportedReason (Severity=Warning) while an
+	// IroncoreMetalMachine sets a RAID layout. metal-operator can not configure RAID controllers yet and ServerClaims
+	// can not pass the layout to its boot flow, hence the layout is not applied. It is removed once no RAID layout is
+	// set. Like QuotaExceeded, True is the unhealthy state, hence it is not part of Ready.
+	RAIDLayoutUnsupportedCondition clusterv1.ConditionType = "RAIDLayoutUnsupported"
+	// RAIDLayoutUnsupportedReason (Severity=Warning) documents a RAID layout which is not applied to the server.
+	RAIDLayoutUnsupportedReason = "RAIDLayoutUnsupported"
+)
+
+const (
+	// ImageResolvedCondition documents the resolution of the OS image of an IroncoreMetalMachine.
+	ImageResolvedCondition clusterv1.ConditionType = "ImageResolved"
+
+	// NoImageReason (Severity=Error) documents an IroncoreMetalMachine for which neither the machine, its class nor
+	// the MachineDefaults of its IroncoreMetalCluster set an image, images or an image catalog.
+	NoImageReason = "NoImage"
+	// ImageCatalogNotFoundReason (Severity=Error) documents a missing IroncoreMetalImageCatalog.
+	ImageCatalogNotFoundReason = "ImageCatalogNotFound"
+	// ImageNotInCatalogReason (Severity=Error) documents a Kubernetes version and architecture without image in the catalog.
+	ImageNotInCatalogReason = "ImageNotInCatalog"
+	// UnsupportedArchitectureReason (Severity=Error) documents a Server of an architecture without image.
+	UnsupportedArchitectureReason = "UnsupportedArchitecture"
+	// WaitingForServerArchitectureReason (Severity=Info) documents an image which is chosen once the Server the
+	// ServerClaim is pinned to is chosen.
+	WaitingForServerArchitectureReason = "WaitingForServerArchitecture"
+	// InvalidImageReason (Severity=Error) documents an image which is not a valid image reference.
+	InvalidImageReason = "InvalidImage"
+	// ImageDigestUnresolvedReason (Severity=Warning) documents an image whose digest could not be resolved from
+	// its registry yet.
+	ImageDigestUnresolvedReason = "ImageDigestUnresolved"
+	// ImagePullSecretNotFoundReason (Severity=Warning) documents a missing or invalid image pull Secret.
+	ImagePullSecretNotFoundReason = "ImagePullSecretNotFound"
+
+	// ImagePullSecretUnsupportedCondition is set to True with the ImagePullSecretUnsupportedReason (Severity=Warning)
+	// while an IroncoreMetalMachine uses an image pull Secret. The controller resolves and verifies the image with its
+	// credentials, but ServerClaims can not pass them to the boot flow of metal-operator, which pulls the image without
+	// credentials. It is removed once no image pull Secret is used. Like QuotaExceeded, True is the unhealthy state,
+	// hence it is not part of Ready.
+	ImagePullSecretUnsupportedCondition clusterv1.ConditionType = "ImagePullSecretUnsupported"
+	// ImagePullSecretUnsupportedReason (Severity=Warning) documents an image pull Secret the boot flow can not use.
+	ImagePullSecretUnsupportedReason = "ImagePullSecretUnsupported"
+
+	// ImageVerifiedCondition documents the verification of the signature of the OS image of an IroncoreMetalMachine.
+	ImageVerifiedCondition clusterv1.ConditionType = "ImageVerified"
+
+	// ImageVerificationFailedReason (Severity=Error) documents an OS image without valid signature of a trusted key.
+	// It is also used as terminal FailureReason of the IroncoreMetalMachine.
+	ImageVerificationFailedReason = "ImageVerificationFailed"
+	// InvalidPublicKeyReason (Severity=Error) documents public keys of the image verification which can not be parsed.
+	// The image is verified once the keys are corrected.
+	InvalidPublicKeyReason = "InvalidPublicKey"
+)
+
+const (
+	// ServerAvailableCondition documents the availability of a Server for the ServerClaim of an IroncoreMetalMachine.
+	ServerAvailableCondition clusterv1.ConditionType = "ServerAvailable"
+
+	// NoMatchingServerReason (Severity=Warning) documents that no available Server matches the ServerSelector of an
+	// IroncoreMetalMachine.
+	NoMatchingServerReason = "NoMatchingServer"
+	// WaitingForServersReason (Severity=Warning) documents that Servers match an IroncoreMetalMachine, but none of them
+	// is available, hence its ServerClaim is not created yet. The message has the numbers of matching, available and
+	// claimed Servers.
+	WaitingForServersReason = "WaitingForServers"
+	// ServerPoolNotFoundReason (Severity=Error) documents a missing IroncoreMetalServerPool.
+	ServerPoolNotFoundReason = "ServerPoolNotFound"
+	// ServerPoolNotAllowedReason (Severity=Error) documents an IroncoreMetalServerPool which does not allow the
+	// cluster of the IroncoreMetalMachine.
+	ServerPoolNotAllowedReason = "ServerPoolNotAllowed"
+	// QuotaExceededReason (Severity=Warning) documents that the ServerClaim of an IroncoreMetalMachine is not created,
+	// because the cluster used up its quota of the Servers the IroncoreMetalMachine matches.
+	QuotaExceededReason = "QuotaExceeded"
+	// QueuedReason (Severity=Info) documents that the ServerClaim of an IroncoreMetalMachine is not created yet,
+	// because machines ahead of it in the queue wait for the same Servers.
+	QueuedReason = "Queued"
+)
+
+const (
+	// QuotaExceededCondition is set to True with the QuotaExceededReason (Severity=Warning) while the ServerClaim of
+	// an IroncoreMetalMachine is refused, because its cluster used up its quota. It is removed once the quota allows
+	// the ServerClaim. Unlike the other conditions, True is the unhealthy state, hence it is not part of Ready.
+	QuotaExceededCondition clusterv1.ConditionType = "QuotaExceeded"
+)
+
+const (
+	// BIOSSettingsAppliedCondition documents whether the Server of an IroncoreMetalMachine applied its BIOS settings.
+	BIOSSettingsAppliedCondition clusterv1.ConditionType = "BIOSSettingsApplied"
+
+	// BIOSSettingsPendingReason (Severity=Info) documents BIOS settings which are not applied yet, because the
+	// ServerClaim is not bound or the Server did not report them yet.
+	BIOSSettingsPendingReason = "BIOSSettingsPending"
+	// BIOSSettingsNotAppliedReason (Severity=Error) documents BIOS settings a bound Server did not report within the
+	// deadline after they were written. metal-operator only applies settings the Server does not report yet, hence
+	// settings the Server reports with another value never converge, and it does not report the settings of Servers
+	// running another BIOS version. It is also used as terminal FailureReason of the IroncoreMetalMachine.
+	BIOSSettingsNotAppliedReason = "BIOSSettingsNotApplied"
+	// BIOSVersionMismatchReason (Severity=Error) documents a bound Server which reports another BIOS version than the
+	// one the BIOS settings are defined for. It is also used as terminal FailureReason of the IroncoreMetalMachine.
+	BIOSVersionMismatchReason = "BIOSVersionMismatch"
+)
+
+const (
+	// FirmwareNonCompliantCondition documents a bound Server of an IroncoreMetalMachine which does not comply with the
+	// firmware policy of the IroncoreMetalMachine, e.g. because the policy changed after the Server was claimed. It is
+	// True with the FirmwareOutdatedReason while the Server runs older firmware than the policy requires, and Unknown
+	// with the FirmwareVersionUnknownReason while metal-operator does not report a version the policy requires.
+	// Unknown versions do not rule out Servers. It is removed once the Server complies. Like QuotaExceeded, True is
+	// the unhealthy state, hence it is not part of Ready.
+	FirmwareNonCompliantCondition clusterv1.ConditionType = "FirmwareNonCompliant"
+
+	// FirmwareOutdatedReason (Severity=Warning) documents a bound Server which runs older firmware than the firmware
+	// policy requires.
+	FirmwareOutdatedReason = "FirmwareOutdated"
+	// FirmwareVersionUnknownReason (Severity=Info) documents a bound Server whose firmware version the firmware policy
+	// requires is not reported, i.e. the Server has no BIOS settings for the BIOS version it runs or its BMC reports
+	// no firmware version.
+	FirmwareVersionUnknownReason = "FirmwareVersionUnknown"
+)
+
+const (
+	// MachineClassUpToDateCondition documents whether an IroncoreMetalMachine is provisioned with the current spec
+	// of its IroncoreMetalMachineClass.
+	MachineClassUpToDateCondition clusterv1.ConditionType = "MachineClassUpToDate"
+
+	// MachineClassNotFoundReason (Severity=Error) documents a missing IroncoreMetalMachineClass.
+	MachineClassNotFoundReason = "MachineClassNotFound"
+	// MachineClassChangedReason (Severity=Warning) documents an IroncoreMetalMachineClass which changed after the
+	// ServerClaim of the IroncoreMetalMachine was created, hence the IroncoreMetalMachineTemplate has to be rolled to
+	// apply it.
+	MachineClassChangedReason = "MachineClassChanged"
+)
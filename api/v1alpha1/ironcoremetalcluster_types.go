@@ -4,6 +4,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -19,6 +20,258 @@ type IroncoreMetalClusterSpec struct {
 	// ControlPlaneEndpoint represents the endpoint used to communicate with the control plane.
 	// +optional
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint,omitempty"`
+
+	// Access configures the users which can log in to the servers of the cluster.
+	// The users are merged into the ignition of every IroncoreMetalMachine of the cluster.
+	// +optional
+	Access *AccessSpec `json:"access,omitempty"`
+
+	// RegistryMirrors configures the container runtime of every server of the cluster to pull images through mirrors.
+	// They are written as containerd hosts.toml files below /etc/containerd/certs.d, which containerd must be
+	// configured to read with config_path.
+	// +optional
+	// +listType=map
+	// +listMapKey=registry
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
+
+	// TrustedCABundle is a PEM encoded bundle of CA certificates which is added to the trust store of every server
+	// of the cluster, e.g. the CA of a corporate proxy or of registry mirrors.
+	// +optional
+	TrustedCABundle string `json:"trustedCABundle,omitempty"`
+
+	// Proxy configures the HTTP proxy used by the container runtime and the kubelet of every server of the cluster.
+	// +optional
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// ImageVerification configures the verification of the OS images of all IroncoreMetalMachines of the cluster.
+	// +optional
+	ImageVerification *ImageVerificationSpec `json:"imageVerification,omitempty"`
+
+	// ImagePullSecretRef references a Secret of type kubernetes.io/dockerconfigjson with the credentials of the
+	// registries of the OS images of all IroncoreMetalMachines of the cluster. The credentials are only used by the
+	// controller to resolve and verify the images, the servers have to be able to pull them without credentials.
+	// IroncoreMetalMachines using the Secret report this by their ImagePullSecretUnsupported condition.
+	// +optional
+	ImagePullSecretRef *corev1.LocalObjectReference `json:"imagePullSecretRef,omitempty"`
+
+	// ServerSelector constrains the Servers the IroncoreMetalMachines of the cluster claim. It is combined with the
+	// ServerSelector of each IroncoreMetalMachine, machines whose selectors conflict with it are rejected.
+	// +optional
+	ServerSelector *metav1.LabelSelector `json:"serverSelector,omitempty"`
+
+	// Quota limits the number of Servers the IroncoreMetalMachines of the cluster claim. ServerClaims beyond the
+	// quota are not created.
+	// +optional
+	Quota *ServerQuota `json:"quota,omitempty"`
+
+	// Priority is the default Priority of the IroncoreMetalMachines of the cluster. Machines of clusters with higher
+	// priority create their ServerClaims first when Servers are scarce.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// MachineDefaults are the settings the IroncoreMetalMachines of the cluster inherit for the fields they leave
+	// empty. The effective settings of each machine are recorded in its status.
+	// +optional
+	MachineDefaults *MachineDefaults `json:"machineDefaults,omitempty"`
+
+	// FirmwarePolicy is the minimum firmware of the Servers of all IroncoreMetalMachines of the cluster. It is combined
+	// with the policies of their classes and defaults, the higher minimum version applies.
+	// +optional
+	FirmwarePolicy *FirmwarePolicy `json:"firmwarePolicy,omitempty"`
+}
+
+// FirmwarePolicy is the minimum firmware of Servers. Servers which run older firmware are not claimed. Versions
+// which are not reported do not rule out Servers, they are reported by the FirmwareNonCompliant condition of the
+// IroncoreMetalMachine with status Unknown. Versions are compared by their numeric components, e.g. 2.10 is newer
+// than 2.9.
+type FirmwarePolicy struct {
+	// MinBIOSVersion is the minimum BIOS version, which is taken from the status of the Server. metal-operator only
+	// reports the version of Servers with BIOS settings for the version they run.
+	// +optional
+	MinBIOSVersion string `json:"minBIOSVersion,omitempty"`
+
+	// MinBMCVersion is the minimum BMC firmware version, which is taken from the status of the BMC the Server
+	// references.
+	// +optional
+	MinBMCVersion string `json:"minBMCVersion,omitempty"`
+}
+
+// MachineDefaults are the default settings of the control plane and worker IroncoreMetalMachines of a cluster.
+type MachineDefaults struct {
+	// ControlPlane are the defaults of the control plane machines.
+	// +optional
+	ControlPlane *MachineSettings `json:"controlPlane,omitempty"`
+
+	// Worker are the defaults of all other machines.
+	// +optional
+	Worker *MachineSettings `json:"worker,omitempty"`
+}
+
+// MachineSettings are the settings of an IroncoreMetalMachine which can be inherited from its cluster. Image,
+// Images and ImageCatalogRef are inherited together, and only if the machine sets none of them.
+type MachineSettings struct {
+	// Image is the boot image of the servers.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Images are the boot images per CPU architecture.
+	// +optional
+	// +listType=map
+	// +listMapKey=architecture
+	Images []ArchitectureImage `json:"images,omitempty"`
+
+	// ImageCatalogRef references the IroncoreMetalImageCatalog the image is resolved from.
+	// +optional
+	ImageCatalogRef *corev1.LocalObjectReference `json:"imageCatalogRef,omitempty"`
+
+	// ImagePullSecretRef references the Secret with the credentials of the registry of the image.
+	// +optional
+	ImagePullSecretRef *corev1.LocalObjectReference `json:"imagePullSecretRef,omitempty"`
+
+	// ServerSelector selects the Servers the machines claim.
+	// +optional
+	ServerSelector *metav1.LabelSelector `json:"serverSelector,omitempty"`
+
+	// BIOSSettings are the BIOS settings of the servers.
+	// +optional
+	BIOSSettings *BIOSSettings `json:"biosSettings,omitempty"`
+
+	// FirmwarePolicy is the minimum firmware of the servers.
+	// +optional
+	FirmwarePolicy *FirmwarePolicy `json:"firmwarePolicy,omitempty"`
+
+	// Metadata configures how the metadata document is exposed to the servers.
+	// +optional
+	Metadata *MetadataSpec `json:"metadata,omitempty"`
+
+	// BootstrapDataMode defines how bootstrap data in cloud-config format is handed to the servers.
+	// +kubebuilder:validation:Enum=Convert;Passthrough
+	// +optional
+	BootstrapDataMode BootstrapDataMode `json:"bootstrapDataMode,omitempty"`
+}
+
+// ServerQuota limits the number of Servers of a cluster, overall and per class of Servers.
+type ServerQuota struct {
+	// MaxServers is the maximum number of Servers of the cluster.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxServers *int32 `json:"maxServers,omitempty"`
+
+	// Classes limit the number of Servers of the cluster matching their selectors. IroncoreMetalMachines only claim
+	// Servers outside of classes whose Servers are all used, and their ServerClaims are always pinned to a Server
+	// so that they count against the classes before metal-operator binds them.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Classes []ServerQuotaClass `json:"classes,omitempty"`
+}
+
+// ServerQuotaClass limits the number of Servers of a cluster matching a selector.
+type ServerQuotaClass struct {
+	// Name is the name of the class.
+	Name string `json:"name"`
+
+	// ServerSelector selects the Servers of the class.
+	ServerSelector metav1.LabelSelector `json:"serverSelector"`
+
+	// MaxServers is the maximum number of Servers of the class.
+	// +kubebuilder:validation:Minimum=0
+	MaxServers int32 `json:"maxServers"`
+}
+
+// ServerQuotaUsage is the number of Servers a cluster claims, overall and per class of its quota.
+type ServerQuotaUsage struct {
+	// Servers is the number of ServerClaims of the cluster.
+	Servers int32 `json:"servers"`
+
+	// Classes are the numbers of Servers of the cluster per class. ServerClaims count against a class once they
+	// reference a Server of the class.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Classes []ServerQuotaClassUsage `json:"classes,omitempty"`
+}
+
+// ServerQuotaClassUsage is the number of Servers a cluster claims of a class of its quota.
+type ServerQuotaClassUsage struct {
+	// Name is the name of the class.
+	Name string `json:"name"`
+
+	// Servers is the number of Servers of the class.
+	Servers int32 `json:"servers"`
+}
+
+// ImageVerificationSpec configures the verification of the cosign signatures of OS images.
+type ImageVerificationSpec struct {
+	// PublicKeys are the PEM encoded public keys of trusted signers. An image is trusted if it has a cosign
+	// signature of any of the keys.
+	// +kubebuilder:validation:MinItems=1
+	PublicKeys []string `json:"publicKeys"`
+}
+
+// RegistryMirror configures the mirrors of a container image registry.
+type RegistryMirror struct {
+	// Registry is the host and optional port of the mirrored registry, e.g. docker.io or registry.example.com:5000.
+	// _default configures mirrors for all registries without own entry.
+	Registry string `json:"registry"`
+
+	// Endpoints are the URLs of the mirrors, which are tried in order before the registry itself.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Pattern=`^https?://`
+	Endpoints []string `json:"endpoints"`
+}
+
+// ProxySpec configures the HTTP proxy used by the servers of a cluster.
+type ProxySpec struct {
+	// HTTPProxy is the proxy URL for HTTP requests.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the proxy URL for HTTPS requests.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy are hosts, domains and CIDRs which are reached without proxy. localhost, the control plane endpoint
+	// and the pod and service networks of the cluster are always reached without proxy.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// AccessSpec configures the users which can log in to the servers of a cluster.
+type AccessSpec struct {
+	// Users are the users added to every server of the cluster.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Users []AccessUser `json:"users,omitempty"`
+
+	// BreakGlass is a user for emergency access, which is only valid until it expires.
+	// +optional
+	BreakGlass *BreakGlassUser `json:"breakGlass,omitempty"`
+}
+
+// AccessUser is a user which can log in to the servers of a cluster.
+type AccessUser struct {
+	// Name is the name of the user.
+	Name string `json:"name"`
+
+	// Groups are the supplementary groups of the user, e.g. wheel or sudo for administrative access.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// SecretRef references a Secret in the namespace of the IroncoreMetalCluster holding the credentials of the user.
+	// The key sshAuthorizedKeys holds the authorized SSH keys, one per line, and the optional key passwordHash
+	// the password hash for console logins.
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// BreakGlassUser is a user for emergency access.
+type BreakGlassUser struct {
+	AccessUser `json:",inline"`
+
+	// ExpiresAt is the time the user expires. It is locked on the servers from then on and not added to
+	// ignitions rendered afterwards.
+	ExpiresAt metav1.Time `json:"expiresAt"`
 }
 
 // IroncoreMetalClusterStatus defines the observed state of IroncoreMetalCluster
@@ -27,6 +280,10 @@ type IroncoreMetalClusterStatus struct {
 	// +optional
 	Ready bool `json:"ready"`
 
+	// QuotaUsage is the usage of the quota of the cluster. It is only reported if a quota is set.
+	// +optional
+	QuotaUsage *ServerQuotaUsage `json:"quotaUsage,omitempty"`
+
 	// Conditions defines current service state of the IroncoreMetalCluster.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
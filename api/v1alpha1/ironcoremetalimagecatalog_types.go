@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultArchitecture is the CPU architecture images are resolved for if nothing else is known about the server.
+	DefaultArchitecture = "amd64"
+
+	// ArchitectureLabel is the label denoting the CPU architecture of a Server in GOARCH notation. Neither
+	// metal-operator nor this provider set it, Servers without it are considered amd64.
+	ArchitectureLabel = "kubernetes.io/arch"
+)
+
+// IroncoreMetalImageCatalogSpec defines the desired state of IroncoreMetalImageCatalog
+type IroncoreMetalImageCatalogSpec struct {
+	// Images are the OS images of the catalog.
+	// +optional
+	// +listType=atomic
+	Images []CatalogImage `json:"images,omitempty"`
+
+	// ImageVerification configures the verification of the images of the catalog. The public keys are trusted
+	// in addition to the ones of the IroncoreMetalCluster.
+	// +optional
+	ImageVerification *ImageVerificationSpec `json:"imageVerification,omitempty"`
+}
+
+// CatalogImage is an OS image of an IroncoreMetalImageCatalog.
+type CatalogImage struct {
+	// KubernetesVersion is the Kubernetes version the image is built for, e.g. v1.31.4.
+	// +kubebuilder:validation:Pattern=`^v?\d+\.\d+\.\d+`
+	KubernetesVersion string `json:"kubernetesVersion"`
+
+	// Architecture is the CPU architecture of the image in GOARCH notation, e.g. amd64 or arm64.
+	// +kubebuilder:default=amd64
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// Image is the OS image reference, e.g. ghcr.io/ironcore-dev/os-images/gardenlinux:1443.3.
+	Image string `json:"image"`
+
+	// Digest is the digest of the image, e.g. sha256:4e5d.... It pins the image reference if set.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// IroncoreMetalImageCatalog is the Schema for the ironcoremetalimagecatalogs API.
+// It maps Kubernetes versions and CPU architectures to the OS images IroncoreMetalMachines are provisioned with.
+type IroncoreMetalImageCatalog struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IroncoreMetalImageCatalogSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IroncoreMetalImageCatalogList contains a list of IroncoreMetalImageCatalog
+type IroncoreMetalImageCatalogList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IroncoreMetalImageCatalog `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IroncoreMetalImageCatalog{}, &IroncoreMetalImageCatalogList{})
+}
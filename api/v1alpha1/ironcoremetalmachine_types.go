@@ -6,7 +6,10 @@ package v1alpha1
 import (
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 const (
@@ -16,6 +19,9 @@ const (
 
 	// DefaultReconcilerRequeue is the default value for the reconcile retry.
 	DefaultReconcilerRequeue = 5 * time.Second
+
+	// ServerRackLabel is the label on Servers which denotes the rack a server is mounted in.
+	ServerRackLabel = "metal.ironcore.dev/rack"
 )
 
 // IroncoreMetalMachineSpec defines the desired state of IroncoreMetalMachine
@@ -25,12 +31,245 @@ type IroncoreMetalMachineSpec struct {
 	ProviderID *string `json:"providerID,omitempty"`
 
 	// Image specifies the boot image to be used for the server.
-	Image string `json:"image"`
+	// It takes precedence over Images and the image resolved from ImageCatalogRef. If neither of them is set, they
+	// are inherited from the MachineDefaults of the IroncoreMetalCluster.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Images specifies the boot images per CPU architecture. The ServerClaim is pinned to a Server of one of the
+	// architectures, and the image is chosen by its architecture before the ServerClaim is created. The architecture
+	// is taken from the kubernetes.io/arch label of the Server, which has to be set by the operator of the servers,
+	// and defaults to amd64. Images takes precedence over ImageCatalogRef.
+	// +optional
+	// +listType=map
+	// +listMapKey=architecture
+	Images []ArchitectureImage `json:"images,omitempty"`
+
+	// ImageCatalogRef references the IroncoreMetalImageCatalog the image is resolved from if neither Image nor
+	// Images are set.
+	// The image is looked up by the Kubernetes version of the Machine and the CPU architecture of the server,
+	// which is taken from the kubernetes.io/arch label of the ServerSelector and defaults to amd64.
+	// +optional
+	ImageCatalogRef *corev1.LocalObjectReference `json:"imageCatalogRef,omitempty"`
+
+	// ImagePullSecretRef references a Secret of type kubernetes.io/dockerconfigjson with the credentials of the
+	// registry of the image. It takes precedence over the ImagePullSecretRef of the IroncoreMetalCluster.
+	// The credentials are only used by the controller to resolve and verify the image. ServerClaims can not pass
+	// credentials to the boot flow, so the servers have to be able to pull the image without them, which the
+	// ImagePullSecretUnsupported condition reminds of.
+	// +optional
+	ImagePullSecretRef *corev1.LocalObjectReference `json:"imagePullSecretRef,omitempty"`
 
 	// ServerSelector specifies matching criteria for labels on Servers.
 	// This is used to claim specific Server types for a IroncoreMetalMachine.
 	// +optional
 	ServerSelector *metav1.LabelSelector `json:"serverSelector,omitempty"`
+
+	// PoolRef references the IroncoreMetalServerPool the Server is claimed from. The ServerSelector only selects
+	// among the Servers of the pool. IroncoreMetalMachines of clusters the pool does not allow are refused.
+	// Without PoolRef, the Servers of all pools are left out and the ServerClaim is pinned to the chosen Server
+	// if pools exist.
+	// +optional
+	PoolRef *corev1.LocalObjectReference `json:"poolRef,omitempty"`
+
+	// Class is the name of the IroncoreMetalMachineClass of the IroncoreMetalMachine. Its ServerSelector is combined
+	// with the one of the machine, its BIOS settings and image are used for the fields the machine leaves empty.
+	// +optional
+	Class string `json:"class,omitempty"`
+
+	// Metadata configures how the metadata document of the IroncoreMetalMachine is exposed to the server.
+	// +optional
+	Metadata *MetadataSpec `json:"metadata,omitempty"`
+
+	// BootstrapDataMode defines how bootstrap data in cloud-config format is handed to the server.
+	// Convert (default) translates it into an ignition, Passthrough stores it unchanged under the ignition key
+	// of the ignition Secret, which metal-operator hands to the boot configuration of the server. Passthrough
+	// therefore requires an OS image whose boot flow reads the data in its format from there.
+	// Talos machine configs are always passed through.
+	// +kubebuilder:validation:Enum=Convert;Passthrough
+	// +optional
+	BootstrapDataMode BootstrapDataMode `json:"bootstrapDataMode,omitempty"`
+
+	// BIOSSettings are written to the bound Server, which is kept powered off until they are written. A Server
+	// which is already powered on is restarted once metal-operator staged them. The IroncoreMetalMachine is ready
+	// once the Server reports them, and fails if the Server does not report them in time. They are reverted when the
+	// IroncoreMetalMachine is deleted.
+	// +optional
+	BIOSSettings *BIOSSettings `json:"biosSettings,omitempty"`
+
+	// RAID is the RAID layout of the server. metal-operator can not configure RAID controllers yet, hence the layout
+	// is not applied and the IroncoreMetalMachine reports the RAIDLayoutUnsupported condition while it is set.
+	// +optional
+	RAID *RAIDSpec `json:"raid,omitempty"`
+
+	// ServerAntiAffinity spreads the servers of sibling IroncoreMetalMachines across topology domains like racks or
+	// chassis. The ServerClaim is pinned to the chosen Server.
+	// +optional
+	// +listType=map
+	// +listMapKey=topologyKey
+	ServerAntiAffinity []ServerAntiAffinityTerm `json:"serverAntiAffinity,omitempty"`
+
+	// PreferredServers rank the Servers matching the ServerSelector, like the preferred scheduling terms of node
+	// affinity. The Server with the highest sum of the weights of the terms selecting it is claimed, Servers no term
+	// selects are still accepted. The ServerClaim is pinned to the chosen Server.
+	// +optional
+	PreferredServers []PreferredServerTerm `json:"preferredServers,omitempty"`
+
+	// Priority orders the IroncoreMetalMachines waiting for Servers when there are fewer Servers than waiting
+	// machines. Control plane machines are admitted before workers, then machines of higher priority before
+	// machines of lower priority. It defaults to the Priority of the IroncoreMetalCluster.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+}
+
+// PreferredServerTerm adds its weight to the score of the Servers it selects.
+type PreferredServerTerm struct {
+	// Weight is added to the score of the Servers selected by Preference.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// Preference selects the preferred Servers by their labels.
+	Preference metav1.LabelSelector `json:"preference"`
+}
+
+// ServerAntiAffinityTerm keeps the server of an IroncoreMetalMachine out of the topology domains of the servers of
+// its sibling IroncoreMetalMachines.
+type ServerAntiAffinityTerm struct {
+	// TopologyKey is the label of Servers whose values denote the topology domains, e.g. metal.ironcore.dev/rack.
+	// Servers without the label are in no domain.
+	// +kubebuilder:validation:MinLength=1
+	TopologyKey string `json:"topologyKey"`
+
+	// Scope defines the sibling IroncoreMetalMachines: the ones of the same MachineDeployment, the control plane
+	// machines or all machines of the cluster.
+	// +kubebuilder:validation:Enum=MachineDeployment;ControlPlane;Cluster
+	Scope AntiAffinityScope `json:"scope"`
+
+	// Mode defines whether servers in the domains of siblings are ruled out (Required) or only avoided if
+	// possible (Preferred).
+	// +kubebuilder:validation:Enum=Required;Preferred
+	// +kubebuilder:default=Required
+	// +optional
+	Mode AntiAffinityMode `json:"mode,omitempty"`
+}
+
+// AntiAffinityScope defines the sibling IroncoreMetalMachines of a ServerAntiAffinityTerm.
+type AntiAffinityScope string
+
+const (
+	// AntiAffinityScopeMachineDeployment are the machines of the same MachineDeployment.
+	AntiAffinityScopeMachineDeployment AntiAffinityScope = "MachineDeployment"
+	// AntiAffinityScopeControlPlane are the control plane machines of the cluster.
+	AntiAffinityScopeControlPlane AntiAffinityScope = "ControlPlane"
+	// AntiAffinityScopeCluster are all machines of the cluster.
+	AntiAffinityScopeCluster AntiAffinityScope = "Cluster"
+)
+
+// AntiAffinityMode defines how strictly a ServerAntiAffinityTerm is enforced.
+type AntiAffinityMode string
+
+const (
+	// AntiAffinityModeRequired rules out servers in the domains of siblings.
+	AntiAffinityModeRequired AntiAffinityMode = "Required"
+	// AntiAffinityModePreferred avoids servers in the domains of siblings if possible.
+	AntiAffinityModePreferred AntiAffinityMode = "Preferred"
+)
+
+// RAIDSpec is the RAID layout of a server.
+type RAIDSpec struct {
+	// LogicalDisks are the logical disks of the RAID controller.
+	// +kubebuilder:validation:MinItems=1
+	LogicalDisks []LogicalDisk `json:"logicalDisks"`
+}
+
+// LogicalDisk is a logical disk of a RAID controller.
+type LogicalDisk struct {
+	// Name is the name of the logical disk.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Level is the RAID level of the logical disk.
+	// +kubebuilder:validation:Enum="0";"1";"5";"6";"10"
+	Level string `json:"level"`
+
+	// Size is the size of the logical disk. It takes the whole physical disks if not set.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// NumberOfPhysicalDisks is the number of physical disks of the logical disk.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumberOfPhysicalDisks int32 `json:"numberOfPhysicalDisks,omitempty"`
+
+	// PhysicalDisks are the names of the physical disks of the logical disk.
+	// +optional
+	PhysicalDisks []string `json:"physicalDisks,omitempty"`
+}
+
+// PlacementStatus is the Server chosen for the ServerClaim of an IroncoreMetalMachine.
+type PlacementStatus struct {
+	// Server is the name of the chosen Server.
+	Server string `json:"server"`
+
+	// Score is the sum of the weights of the PreferredServers terms selecting the Server, reduced by 100 for each
+	// violated preferred anti-affinity term.
+	Score int64 `json:"score"`
+}
+
+// BIOSStatus records the BIOS settings an IroncoreMetalMachine wrote to its Server.
+type BIOSStatus struct {
+	// Server is the name of the Server the settings were written to.
+	Server string `json:"server"`
+
+	// Version is the BIOS version the settings were written for.
+	Version string `json:"version"`
+
+	// Settings are the written settings.
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// Previous are the values the Server defined for the settings before they were written. Settings missing here
+	// were not defined by the Server and are removed when the written settings are reverted.
+	// +optional
+	Previous map[string]string `json:"previous,omitempty"`
+
+	// Restarted tells whether the Server was restarted to apply the settings.
+	// +optional
+	Restarted bool `json:"restarted,omitempty"`
+
+	// WrittenAt is the time the settings were written to the Server. Settings the Server does not apply in time
+	// fail the IroncoreMetalMachine.
+	// +optional
+	WrittenAt *metav1.Time `json:"writtenAt,omitempty"`
+}
+
+// BootstrapDataMode defines how bootstrap data which is not in ignition format is handed to the server.
+type BootstrapDataMode string
+
+const (
+	// BootstrapDataModeConvert converts cloud-config bootstrap data into an ignition.
+	BootstrapDataModeConvert BootstrapDataMode = "Convert"
+	// BootstrapDataModePassthrough hands bootstrap data to the server unchanged.
+	BootstrapDataModePassthrough BootstrapDataMode = "Passthrough"
+)
+
+// ArchitectureImage is the boot image for servers of a CPU architecture.
+type ArchitectureImage struct {
+	// Architecture is the CPU architecture in GOARCH notation, e.g. amd64 or arm64.
+	Architecture string `json:"architecture"`
+
+	// Image is the boot image for servers of the architecture.
+	Image string `json:"image"`
+}
+
+// MetadataSpec configures the metadata document of an IroncoreMetalMachine.
+type MetadataSpec struct {
+	// IgnitionPath is the path of the file in the ignition the metadata document is written to,
+	// e.g. /etc/metal/metadata.json. The document is not embedded into the ignition if empty,
+	// nor into bootstrap data which is passed through unchanged.
+	// +optional
+	IgnitionPath string `json:"ignitionPath,omitempty"`
 }
 
 // IroncoreMetalMachineStatus defines the observed state of IroncoreMetalMachine
@@ -76,6 +315,55 @@ type IroncoreMetalMachineStatus struct {
 	// controller's output.
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// MetadataSecretRef is a reference to the Secret holding the metadata document of the IroncoreMetalMachine.
+	// +optional
+	MetadataSecretRef *corev1.LocalObjectReference `json:"metadataSecretRef,omitempty"`
+
+	// Image is the image reference the server is provisioned with, as it was before pinning it to ImageDigest.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ImageDigest is the digest the image is pinned to.
+	// +optional
+	ImageDigest string `json:"imageDigest,omitempty"`
+
+	// ImageVerificationHash is the hash of the image and the public keys its signature was verified with. The
+	// signature is verified again once either of them changes.
+	// +optional
+	ImageVerificationHash string `json:"imageVerificationHash,omitempty"`
+
+	// Placement is the Server the controller chose for the ServerClaim.
+	// +optional
+	Placement *PlacementStatus `json:"placement,omitempty"`
+
+	// EffectiveSettings are the settings the IroncoreMetalMachine is provisioned with, after inheriting the fields
+	// it leaves empty from its IroncoreMetalMachineClass and the MachineDefaults of the IroncoreMetalCluster.
+	// They are kept once the ServerClaim of the IroncoreMetalMachine is created, later changes of the class or the
+	// defaults only apply to new machines.
+	// +optional
+	EffectiveSettings *MachineSettings `json:"effectiveSettings,omitempty"`
+
+	// MachineClassHash is the hash of the spec of the IroncoreMetalMachineClass the effective settings were
+	// inherited from. Like the effective settings, it is kept once the ServerClaim is created. Later changes of the
+	// class are not applied, the MachineClassUpToDate condition tells that the template has to be rolled.
+	// +optional
+	MachineClassHash string `json:"machineClassHash,omitempty"`
+
+	// BIOS records the BIOS settings written to the Server, so that they are reverted when the IroncoreMetalMachine
+	// is deleted.
+	// +optional
+	BIOS *BIOSStatus `json:"bios,omitempty"`
+
+	// QueuePosition is the position of the IroncoreMetalMachine among the machines waiting for the Servers it
+	// matches, starting at 1. It is only set while the ServerClaim is not created, because there are fewer Servers than
+	// machines ahead of it.
+	// +optional
+	QueuePosition *int32 `json:"queuePosition,omitempty"`
+
+	// Conditions defines current service state of the IroncoreMetalMachine.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -99,6 +387,16 @@ type IroncoreMetalMachineList struct {
 	Items           []IroncoreMetalMachine `json:"items"`
 }
 
+// GetConditions returns the observations of the operational state of the IroncoreMetalMachine resource.
+func (m *IroncoreMetalMachine) GetConditions() clusterv1.Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions sets the underlying service state of the IroncoreMetalMachine to the predescribed clusterv1.Conditions.
+func (m *IroncoreMetalMachine) SetConditions(conditions clusterv1.Conditions) {
+	m.Status.Conditions = conditions
+}
+
 func init() {
 	SchemeBuilder.Register(&IroncoreMetalMachine{}, &IroncoreMetalMachineList{})
 }
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IroncoreMetalMachineClassSpec defines the desired state of IroncoreMetalMachineClass
+type IroncoreMetalMachineClassSpec struct {
+	// ServerSelector selects the Servers of the class. It is combined with the ServerSelector of the
+	// IroncoreMetalMachines of the class.
+	// +optional
+	ServerSelector *metav1.LabelSelector `json:"serverSelector,omitempty"`
+
+	// BIOSSettings is the BIOS profile of the Servers of the class.
+	// +optional
+	BIOSSettings *BIOSSettings `json:"biosSettings,omitempty"`
+
+	// FirmwarePolicy is the minimum firmware of the Servers of the class.
+	// +optional
+	FirmwarePolicy *FirmwarePolicy `json:"firmwarePolicy,omitempty"`
+
+	// Image is the default boot image of the IroncoreMetalMachines of the class.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Images are the default boot images per CPU architecture of the IroncoreMetalMachines of the class.
+	// +optional
+	// +listType=map
+	// +listMapKey=architecture
+	Images []ArchitectureImage `json:"images,omitempty"`
+
+	// ImageCatalogRef references the IroncoreMetalImageCatalog the default image of the IroncoreMetalMachines of
+	// the class is resolved from.
+	// +optional
+	ImageCatalogRef *corev1.LocalObjectReference `json:"imageCatalogRef,omitempty"`
+}
+
+// BIOSSettings are the BIOS settings of a server.
+type BIOSSettings struct {
+	// Version is the BIOS version the settings are defined for. metal-operator only applies and reports the BIOS
+	// settings of the version a Server runs, hence Servers reporting another version are not claimed.
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+
+	// Settings are the BIOS attributes and their values, e.g. ProcVirtualization: Enabled.
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// IroncoreMetalMachineClass is the Schema for the ironcoremetalmachineclasses API.
+// It bundles the Server selection, BIOS profile and default image of a size class of IroncoreMetalMachines, which
+// reference it by name.
+type IroncoreMetalMachineClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IroncoreMetalMachineClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IroncoreMetalMachineClassList contains a list of IroncoreMetalMachineClass
+type IroncoreMetalMachineClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IroncoreMetalMachineClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IroncoreMetalMachineClass{}, &IroncoreMetalMachineClassList{})
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IroncoreMetalServerPoolSpec defines the desired state of IroncoreMetalServerPool
+type IroncoreMetalServerPoolSpec struct {
+	// ServerSelector selects the Servers of the pool.
+	ServerSelector metav1.LabelSelector `json:"serverSelector"`
+
+	// AllowedNamespaces are the namespaces whose clusters may use the pool.
+	// +optional
+	// +listType=set
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// AllowedClusters are the clusters which may use the pool in addition to the ones of AllowedNamespaces.
+	// The pool may be used by all clusters if neither AllowedNamespaces nor AllowedClusters are set.
+	// +optional
+	// +listType=atomic
+	AllowedClusters []ClusterReference `json:"allowedClusters,omitempty"`
+}
+
+// ClusterReference refers to a Cluster API Cluster.
+type ClusterReference struct {
+	// Namespace is the namespace of the Cluster.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the Cluster.
+	Name string `json:"name"`
+}
+
+// IroncoreMetalServerPoolStatus defines the observed state of IroncoreMetalServerPool
+type IroncoreMetalServerPoolStatus struct {
+	// Total is the number of Servers of the pool.
+	// +optional
+	Total int32 `json:"total,omitempty"`
+
+	// Available is the number of Servers of the pool which can be claimed.
+	// +optional
+	Available int32 `json:"available,omitempty"`
+
+	// Claimed is the number of Servers of the pool which are claimed.
+	// +optional
+	Claimed int32 `json:"claimed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Total",type="integer",JSONPath=".status.total",description="Number of Servers of the pool"
+// +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.available",description="Number of Servers which can be claimed"
+// +kubebuilder:printcolumn:name="Claimed",type="integer",JSONPath=".status.claimed",description="Number of Servers which are claimed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// IroncoreMetalServerPool is the Schema for the ironcoremetalserverpools API.
+// It reserves a set of Servers for the clusters which are allowed to use it. IroncoreMetalMachines without
+// PoolRef never claim the Servers of a pool.
+type IroncoreMetalServerPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IroncoreMetalServerPoolSpec   `json:"spec,omitempty"`
+	Status IroncoreMetalServerPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IroncoreMetalServerPoolList contains a list of IroncoreMetalServerPool
+type IroncoreMetalServerPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IroncoreMetalServerPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IroncoreMetalServerPool{}, &IroncoreMetalServerPoolList{})
+}
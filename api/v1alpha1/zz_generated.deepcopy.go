@@ -8,17 +8,218 @@
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessSpec) DeepCopyInto(out *AccessSpec) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]AccessUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BreakGlass != nil {
+		in, out := &in.BreakGlass, &out.BreakGlass
+		*out = new(BreakGlassUser)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessSpec.
+func (in *AccessSpec) DeepCopy() *AccessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessUser) DeepCopyInto(out *AccessUser) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessUser.
+func (in *AccessUser) DeepCopy() *AccessUser {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchitectureImage) DeepCopyInto(out *ArchitectureImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchitectureImage.
+func (in *ArchitectureImage) DeepCopy() *ArchitectureImage {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchitectureImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BIOSSettings) DeepCopyInto(out *BIOSSettings) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BIOSSettings.
+func (in *BIOSSettings) DeepCopy() *BIOSSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(BIOSSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BIOSStatus) DeepCopyInto(out *BIOSStatus) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Previous != nil {
+		in, out := &in.Previous, &out.Previous
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.WrittenAt != nil {
+		in, out := &in.WrittenAt, &out.WrittenAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BIOSStatus.
+func (in *BIOSStatus) DeepCopy() *BIOSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BIOSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BreakGlassUser) DeepCopyInto(out *BreakGlassUser) {
+	*out = *in
+	in.AccessUser.DeepCopyInto(&out.AccessUser)
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BreakGlassUser.
+func (in *BreakGlassUser) DeepCopy() *BreakGlassUser {
+	if in == nil {
+		return nil
+	}
+	out := new(BreakGlassUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogImage) DeepCopyInto(out *CatalogImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogImage.
+func (in *CatalogImage) DeepCopy() *CatalogImage {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterReference) DeepCopyInto(out *ClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReference.
+func (in *ClusterReference) DeepCopy() *ClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwarePolicy) DeepCopyInto(out *FirmwarePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwarePolicy.
+func (in *FirmwarePolicy) DeepCopy() *FirmwarePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwarePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVerificationSpec) DeepCopyInto(out *ImageVerificationSpec) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVerificationSpec.
+func (in *ImageVerificationSpec) DeepCopy() *ImageVerificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVerificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IroncoreMetalCluster) DeepCopyInto(out *IroncoreMetalCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -76,6 +277,53 @@ func (in *IroncoreMetalClusterList) DeepCopyObject() runtime.Object {
 func (in *IroncoreMetalClusterSpec) DeepCopyInto(out *IroncoreMetalClusterSpec) {
 	*out = *in
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.Access != nil {
+		in, out := &in.Access, &out.Access
+		*out = new(AccessSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]RegistryMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageVerification != nil {
+		in, out := &in.ImageVerification, &out.ImageVerification
+		*out = new(ImageVerificationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecretRef != nil {
+		in, out := &in.ImagePullSecretRef, &out.ImagePullSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ServerSelector != nil {
+		in, out := &in.ServerSelector, &out.ServerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(ServerQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MachineDefaults != nil {
+		in, out := &in.MachineDefaults, &out.MachineDefaults
+		*out = new(MachineDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FirmwarePolicy != nil {
+		in, out := &in.FirmwarePolicy, &out.FirmwarePolicy
+		*out = new(FirmwarePolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalClusterSpec.
@@ -91,6 +339,11 @@ func (in *IroncoreMetalClusterSpec) DeepCopy() *IroncoreMetalClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IroncoreMetalClusterStatus) DeepCopyInto(out *IroncoreMetalClusterStatus) {
 	*out = *in
+	if in.QuotaUsage != nil {
+		in, out := &in.QuotaUsage, &out.QuotaUsage
+		*out = new(ServerQuotaUsage)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(v1beta1.Conditions, len(*in))
@@ -111,26 +364,25 @@ func (in *IroncoreMetalClusterStatus) DeepCopy() *IroncoreMetalClusterStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachine) DeepCopyInto(out *IroncoreMetalMachine) {
+func (in *IroncoreMetalImageCatalog) DeepCopyInto(out *IroncoreMetalImageCatalog) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachine.
-func (in *IroncoreMetalMachine) DeepCopy() *IroncoreMetalMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalImageCatalog.
+func (in *IroncoreMetalImageCatalog) DeepCopy() *IroncoreMetalImageCatalog {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachine)
+	out := new(IroncoreMetalImageCatalog)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IroncoreMetalMachine) DeepCopyObject() runtime.Object {
+func (in *IroncoreMetalImageCatalog) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -138,31 +390,31 @@ func (in *IroncoreMetalMachine) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachineList) DeepCopyInto(out *IroncoreMetalMachineList) {
+func (in *IroncoreMetalImageCatalogList) DeepCopyInto(out *IroncoreMetalImageCatalogList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]IroncoreMetalMachine, len(*in))
+		*out = make([]IroncoreMetalImageCatalog, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineList.
-func (in *IroncoreMetalMachineList) DeepCopy() *IroncoreMetalMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalImageCatalogList.
+func (in *IroncoreMetalImageCatalogList) DeepCopy() *IroncoreMetalImageCatalogList {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachineList)
+	out := new(IroncoreMetalImageCatalogList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IroncoreMetalMachineList) DeepCopyObject() runtime.Object {
+func (in *IroncoreMetalImageCatalogList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -170,70 +422,77 @@ func (in *IroncoreMetalMachineList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachineSpec) DeepCopyInto(out *IroncoreMetalMachineSpec) {
+func (in *IroncoreMetalImageCatalogSpec) DeepCopyInto(out *IroncoreMetalImageCatalogSpec) {
 	*out = *in
-	if in.ProviderID != nil {
-		in, out := &in.ProviderID, &out.ProviderID
-		*out = new(string)
-		**out = **in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]CatalogImage, len(*in))
+		copy(*out, *in)
 	}
-	if in.ServerSelector != nil {
-		in, out := &in.ServerSelector, &out.ServerSelector
-		*out = new(v1.LabelSelector)
+	if in.ImageVerification != nil {
+		in, out := &in.ImageVerification, &out.ImageVerification
+		*out = new(ImageVerificationSpec)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineSpec.
-func (in *IroncoreMetalMachineSpec) DeepCopy() *IroncoreMetalMachineSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalImageCatalogSpec.
+func (in *IroncoreMetalImageCatalogSpec) DeepCopy() *IroncoreMetalImageCatalogSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachineSpec)
+	out := new(IroncoreMetalImageCatalogSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachineStatus) DeepCopyInto(out *IroncoreMetalMachineStatus) {
+func (in *IroncoreMetalMachine) DeepCopyInto(out *IroncoreMetalMachine) {
 	*out = *in
-	if in.FailureMessage != nil {
-		in, out := &in.FailureMessage, &out.FailureMessage
-		*out = new(string)
-		**out = **in
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineStatus.
-func (in *IroncoreMetalMachineStatus) DeepCopy() *IroncoreMetalMachineStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachine.
+func (in *IroncoreMetalMachine) DeepCopy() *IroncoreMetalMachine {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachineStatus)
+	out := new(IroncoreMetalMachine)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IroncoreMetalMachine) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachineTemplate) DeepCopyInto(out *IroncoreMetalMachineTemplate) {
+func (in *IroncoreMetalMachineClass) DeepCopyInto(out *IroncoreMetalMachineClass) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplate.
-func (in *IroncoreMetalMachineTemplate) DeepCopy() *IroncoreMetalMachineTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineClass.
+func (in *IroncoreMetalMachineClass) DeepCopy() *IroncoreMetalMachineClass {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachineTemplate)
+	out := new(IroncoreMetalMachineClass)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IroncoreMetalMachineTemplate) DeepCopyObject() runtime.Object {
+func (in *IroncoreMetalMachineClass) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -241,31 +500,31 @@ func (in *IroncoreMetalMachineTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachineTemplateList) DeepCopyInto(out *IroncoreMetalMachineTemplateList) {
+func (in *IroncoreMetalMachineClassList) DeepCopyInto(out *IroncoreMetalMachineClassList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]IroncoreMetalMachineTemplate, len(*in))
+		*out = make([]IroncoreMetalMachineClass, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplateList.
-func (in *IroncoreMetalMachineTemplateList) DeepCopy() *IroncoreMetalMachineTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineClassList.
+func (in *IroncoreMetalMachineClassList) DeepCopy() *IroncoreMetalMachineClassList {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachineTemplateList)
+	out := new(IroncoreMetalMachineClassList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IroncoreMetalMachineTemplateList) DeepCopyObject() runtime.Object {
+func (in *IroncoreMetalMachineClassList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -273,34 +532,694 @@ func (in *IroncoreMetalMachineTemplateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachineTemplateResource) DeepCopyInto(out *IroncoreMetalMachineTemplateResource) {
+func (in *IroncoreMetalMachineClassSpec) DeepCopyInto(out *IroncoreMetalMachineClassSpec) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.ServerSelector != nil {
+		in, out := &in.ServerSelector, &out.ServerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BIOSSettings != nil {
+		in, out := &in.BIOSSettings, &out.BIOSSettings
+		*out = new(BIOSSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FirmwarePolicy != nil {
+		in, out := &in.FirmwarePolicy, &out.FirmwarePolicy
+		*out = new(FirmwarePolicy)
+		**out = **in
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ArchitectureImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageCatalogRef != nil {
+		in, out := &in.ImageCatalogRef, &out.ImageCatalogRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplateResource.
-func (in *IroncoreMetalMachineTemplateResource) DeepCopy() *IroncoreMetalMachineTemplateResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineClassSpec.
+func (in *IroncoreMetalMachineClassSpec) DeepCopy() *IroncoreMetalMachineClassSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachineTemplateResource)
+	out := new(IroncoreMetalMachineClassSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IroncoreMetalMachineTemplateSpec) DeepCopyInto(out *IroncoreMetalMachineTemplateSpec) {
+func (in *IroncoreMetalMachineList) DeepCopyInto(out *IroncoreMetalMachineList) {
 	*out = *in
-	in.Template.DeepCopyInto(&out.Template)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IroncoreMetalMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplateSpec.
-func (in *IroncoreMetalMachineTemplateSpec) DeepCopy() *IroncoreMetalMachineTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineList.
+func (in *IroncoreMetalMachineList) DeepCopy() *IroncoreMetalMachineList {
 	if in == nil {
 		return nil
 	}
-	out := new(IroncoreMetalMachineTemplateSpec)
+	out := new(IroncoreMetalMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IroncoreMetalMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalMachineSpec) DeepCopyInto(out *IroncoreMetalMachineSpec) {
+	*out = *in
+	if in.ProviderID != nil {
+		in, out := &in.ProviderID, &out.ProviderID
+		*out = new(string)
+		**out = **in
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ArchitectureImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageCatalogRef != nil {
+		in, out := &in.ImageCatalogRef, &out.ImageCatalogRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ImagePullSecretRef != nil {
+		in, out := &in.ImagePullSecretRef, &out.ImagePullSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ServerSelector != nil {
+		in, out := &in.ServerSelector, &out.ServerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PoolRef != nil {
+		in, out := &in.PoolRef, &out.PoolRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(MetadataSpec)
+		**out = **in
+	}
+	if in.BIOSSettings != nil {
+		in, out := &in.BIOSSettings, &out.BIOSSettings
+		*out = new(BIOSSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RAID != nil {
+		in, out := &in.RAID, &out.RAID
+		*out = new(RAIDSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServerAntiAffinity != nil {
+		in, out := &in.ServerAntiAffinity, &out.ServerAntiAffinity
+		*out = make([]ServerAntiAffinityTerm, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreferredServers != nil {
+		in, out := &in.PreferredServers, &out.PreferredServers
+		*out = make([]PreferredServerTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineSpec.
+func (in *IroncoreMetalMachineSpec) DeepCopy() *IroncoreMetalMachineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalMachineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalMachineStatus) DeepCopyInto(out *IroncoreMetalMachineStatus) {
+	*out = *in
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+	if in.MetadataSecretRef != nil {
+		in, out := &in.MetadataSecretRef, &out.MetadataSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(PlacementStatus)
+		**out = **in
+	}
+	if in.EffectiveSettings != nil {
+		in, out := &in.EffectiveSettings, &out.EffectiveSettings
+		*out = new(MachineSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BIOS != nil {
+		in, out := &in.BIOS, &out.BIOS
+		*out = new(BIOSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueuePosition != nil {
+		in, out := &in.QueuePosition, &out.QueuePosition
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineStatus.
+func (in *IroncoreMetalMachineStatus) DeepCopy() *IroncoreMetalMachineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalMachineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalMachineTemplate) DeepCopyInto(out *IroncoreMetalMachineTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplate.
+func (in *IroncoreMetalMachineTemplate) DeepCopy() *IroncoreMetalMachineTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalMachineTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IroncoreMetalMachineTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalMachineTemplateList) DeepCopyInto(out *IroncoreMetalMachineTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IroncoreMetalMachineTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplateList.
+func (in *IroncoreMetalMachineTemplateList) DeepCopy() *IroncoreMetalMachineTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalMachineTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IroncoreMetalMachineTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalMachineTemplateResource) DeepCopyInto(out *IroncoreMetalMachineTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplateResource.
+func (in *IroncoreMetalMachineTemplateResource) DeepCopy() *IroncoreMetalMachineTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalMachineTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalMachineTemplateSpec) DeepCopyInto(out *IroncoreMetalMachineTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalMachineTemplateSpec.
+func (in *IroncoreMetalMachineTemplateSpec) DeepCopy() *IroncoreMetalMachineTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalMachineTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalServerPool) DeepCopyInto(out *IroncoreMetalServerPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalServerPool.
+func (in *IroncoreMetalServerPool) DeepCopy() *IroncoreMetalServerPool {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalServerPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IroncoreMetalServerPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalServerPoolList) DeepCopyInto(out *IroncoreMetalServerPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IroncoreMetalServerPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalServerPoolList.
+func (in *IroncoreMetalServerPoolList) DeepCopy() *IroncoreMetalServerPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalServerPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IroncoreMetalServerPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalServerPoolSpec) DeepCopyInto(out *IroncoreMetalServerPoolSpec) {
+	*out = *in
+	in.ServerSelector.DeepCopyInto(&out.ServerSelector)
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedClusters != nil {
+		in, out := &in.AllowedClusters, &out.AllowedClusters
+		*out = make([]ClusterReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalServerPoolSpec.
+func (in *IroncoreMetalServerPoolSpec) DeepCopy() *IroncoreMetalServerPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalServerPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IroncoreMetalServerPoolStatus) DeepCopyInto(out *IroncoreMetalServerPoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IroncoreMetalServerPoolStatus.
+func (in *IroncoreMetalServerPoolStatus) DeepCopy() *IroncoreMetalServerPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IroncoreMetalServerPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalDisk) DeepCopyInto(out *LogicalDisk) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.PhysicalDisks != nil {
+		in, out := &in.PhysicalDisks, &out.PhysicalDisks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalDisk.
+func (in *LogicalDisk) DeepCopy() *LogicalDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(LogicalDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDefaults) DeepCopyInto(out *MachineDefaults) {
+	*out = *in
+	if in.ControlPlane != nil {
+		in, out := &in.ControlPlane, &out.ControlPlane
+		*out = new(MachineSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Worker != nil {
+		in, out := &in.Worker, &out.Worker
+		*out = new(MachineSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDefaults.
+func (in *MachineDefaults) DeepCopy() *MachineDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineSettings) DeepCopyInto(out *MachineSettings) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ArchitectureImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageCatalogRef != nil {
+		in, out := &in.ImageCatalogRef, &out.ImageCatalogRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ImagePullSecretRef != nil {
+		in, out := &in.ImagePullSecretRef, &out.ImagePullSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ServerSelector != nil {
+		in, out := &in.ServerSelector, &out.ServerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BIOSSettings != nil {
+		in, out := &in.BIOSSettings, &out.BIOSSettings
+		*out = new(BIOSSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FirmwarePolicy != nil {
+		in, out := &in.FirmwarePolicy, &out.FirmwarePolicy
+		*out = new(FirmwarePolicy)
+		**out = **in
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(MetadataSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineSettings.
+func (in *MachineSettings) DeepCopy() *MachineSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataSpec) DeepCopyInto(out *MetadataSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataSpec.
+func (in *MetadataSpec) DeepCopy() *MetadataSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementStatus) DeepCopyInto(out *PlacementStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementStatus.
+func (in *PlacementStatus) DeepCopy() *PlacementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreferredServerTerm) DeepCopyInto(out *PreferredServerTerm) {
+	*out = *in
+	in.Preference.DeepCopyInto(&out.Preference)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreferredServerTerm.
+func (in *PreferredServerTerm) DeepCopy() *PreferredServerTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(PreferredServerTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
+func (in *ProxySpec) DeepCopy() *ProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RAIDSpec) DeepCopyInto(out *RAIDSpec) {
+	*out = *in
+	if in.LogicalDisks != nil {
+		in, out := &in.LogicalDisks, &out.LogicalDisks
+		*out = make([]LogicalDisk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RAIDSpec.
+func (in *RAIDSpec) DeepCopy() *RAIDSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RAIDSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryMirror) DeepCopyInto(out *RegistryMirror) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryMirror.
+func (in *RegistryMirror) DeepCopy() *RegistryMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerAntiAffinityTerm) DeepCopyInto(out *ServerAntiAffinityTerm) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAntiAffinityTerm.
+func (in *ServerAntiAffinityTerm) DeepCopy() *ServerAntiAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerAntiAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerQuota) DeepCopyInto(out *ServerQuota) {
+	*out = *in
+	if in.MaxServers != nil {
+		in, out := &in.MaxServers, &out.MaxServers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Classes != nil {
+		in, out := &in.Classes, &out.Classes
+		*out = make([]ServerQuotaClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerQuota.
+func (in *ServerQuota) DeepCopy() *ServerQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerQuotaClass) DeepCopyInto(out *ServerQuotaClass) {
+	*out = *in
+	in.ServerSelector.DeepCopyInto(&out.ServerSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerQuotaClass.
+func (in *ServerQuotaClass) DeepCopy() *ServerQuotaClass {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerQuotaClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerQuotaClassUsage) DeepCopyInto(out *ServerQuotaClassUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerQuotaClassUsage.
+func (in *ServerQuotaClassUsage) DeepCopy() *ServerQuotaClassUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerQuotaClassUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerQuotaUsage) DeepCopyInto(out *ServerQuotaUsage) {
+	*out = *in
+	if in.Classes != nil {
+		in, out := &in.Classes, &out.Classes
+		*out = make([]ServerQuotaClassUsage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerQuotaUsage.
+func (in *ServerQuotaUsage) DeepCopy() *ServerQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerQuotaUsage)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -5,8 +5,12 @@ package main
 
 import (
 	"crypto/tls"
+	"errors"
 	"flag"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/record"
@@ -27,6 +31,9 @@ import (
 
 	infrastructurev1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
 	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/controller"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/ignition"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry"
+	webhookv1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/webhook/v1alpha1"
 	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
@@ -50,6 +57,11 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var ignitionPartsAddr string
+	var ignitionPartsURL string
+	var ignitionPartsCertDir string
+	var registryConfig string
+	var insecureRegistries []string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -60,6 +72,21 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&ignitionPartsAddr, "ignition-parts-bind-address", "",
+		"The address the ignition part server binds to. Leave empty to disable serving the parts of split ignitions.")
+	flag.StringVar(&ignitionPartsURL, "ignition-parts-url", "",
+		"The HTTPS base URL under which servers reach the ignition part server, e.g. https://10.0.0.1:8082.")
+	flag.StringVar(&ignitionPartsCertDir, "ignition-parts-cert-dir", "",
+		"The directory with the serving certificate (tls.crt, tls.key) of the ignition part server. "+
+			"Ignitions with parts trust the CA in ca.crt of the directory if it exists.")
+	flag.StringVar(&registryConfig, "registry-config", "",
+		"The path of a docker config.json with the pull secrets of the registries OS images are resolved from.")
+	flag.Func("insecure-registries",
+		"A comma separated list of registries which are accessed via plain HTTP, e.g. localhost:5000.",
+		func(value string) error {
+			insecureRegistries = append(insecureRegistries, strings.Split(value, ",")...)
+			return nil
+		})
 	opts := zap.Options{
 		Development: true,
 	}
@@ -68,6 +95,21 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// The parts of split ignitions contain the certificates and keys of the cluster.
+	if ignitionPartsURL != "" && !strings.HasPrefix(ignitionPartsURL, "https://") {
+		setupLog.Error(nil, "the ignition parts URL must be an HTTPS URL", "URL", ignitionPartsURL)
+		os.Exit(1)
+	}
+	if ignitionPartsAddr != "" && ignitionPartsCertDir == "" {
+		setupLog.Error(nil, "the ignition part server requires a certificate directory")
+		os.Exit(1)
+	}
+	ignitionPartsCA, err := readOptionalFile(ignitionPartsCertDir, ignition.PartServerCAName)
+	if err != nil {
+		setupLog.Error(err, "unable to read the CA of the ignition part server")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -131,12 +173,49 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controller.IroncoreMetalMachineReconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		IgnitionPartsURL: ignitionPartsURL,
+		IgnitionPartsCA:  ignitionPartsCA,
+		Registry:         registryClient(registryConfig, insecureRegistries),
+		APIReader:        mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "IroncoreMetalMachine")
+		os.Exit(1)
+	}
+	if err = (&controller.IroncoreMetalServerPoolReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "IroncoreMetalMachine")
+		setupLog.Error(err, "unable to create controller", "controller", "IroncoreMetalServerPool")
 		os.Exit(1)
 	}
+	// The webhook server needs a serving certificate, hence webhooks are only served if the deployment enables them.
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = webhookv1alpha1.SetupIroncoreMetalClusterWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "IroncoreMetalCluster")
+			os.Exit(1)
+		}
+		if err = webhookv1alpha1.SetupIroncoreMetalMachineWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "IroncoreMetalMachine")
+			os.Exit(1)
+		}
+		if err = webhookv1alpha1.SetupIroncoreMetalMachineTemplateWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "IroncoreMetalMachineTemplate")
+			os.Exit(1)
+		}
+	}
+	if ignitionPartsAddr != "" {
+		if err := mgr.Add(&ignition.PartServer{
+			Client:      mgr.GetClient(),
+			BindAddress: ignitionPartsAddr,
+			CertDir:     ignitionPartsCertDir,
+			TLSOpts:     tlsOpts,
+		}); err != nil {
+			setupLog.Error(err, "unable to add ignition part server")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -154,3 +233,24 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// readOptionalFile returns the contents of the file in the directory, or nil if the directory is not set or the
+// file does not exist.
+func readOptionalFile(dir, name string) ([]byte, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func registryClient(config string, insecureRegistries []string) *registry.Client {
+	c := &registry.Client{PlainHTTPRegistries: insecureRegistries}
+	if config != "" {
+		c.Keychain = registry.DockerConfigFile(config)
+	}
+	return c
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bios writes the BIOS settings of IroncoreMetalMachines to their Servers, checks whether they are applied
+// and reverts them.
+package bios
+
+import (
+	"slices"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+const (
+	// RebootNeededCondition is the condition metal-operator adds to a Server once it staged BIOS settings which are
+	// applied by the next boot. metal-operator does not remove it again.
+	RebootNeededCondition = "Reboot needed"
+	// RestartOperation is the Redfish reset type requested by the metal.ironcore.dev/operation annotation to restart
+	// a Server.
+	RestartOperation = "ForceRestart"
+)
+
+// Defined returns the values the Server defines for the settings in its BIOS settings for the version. Settings the
+// Server does not define are missing.
+func Defined(server *metalv1alpha1.Server, version string, settings map[string]string) map[string]string {
+	defined := map[string]string{}
+	for _, bios := range server.Spec.BIOS {
+		if bios.Version != version {
+			continue
+		}
+		for name := range settings {
+			if value, ok := bios.Settings[name]; ok {
+				defined[name] = value
+			}
+		}
+	}
+	return defined
+}
+
+// SetDesired merges the settings into the BIOS settings of the Server for the version and reports whether the spec
+// of the Server changed. Settings of the Server which are not part of the settings are kept.
+func SetDesired(server *metalv1alpha1.Server, version string, settings map[string]string) bool {
+	for i := range server.Spec.BIOS {
+		bios := &server.Spec.BIOS[i]
+		if bios.Version != version {
+			continue
+		}
+		changed := false
+		for name, value := range settings {
+			if current, ok := bios.Settings[name]; ok && current == value {
+				continue
+			}
+			if bios.Settings == nil {
+				bios.Settings = map[string]string{}
+			}
+			bios.Settings[name] = value
+			changed = true
+		}
+		return changed
+	}
+
+	desired := make(map[string]string, len(settings))
+	for name, value := range settings {
+		desired[name] = value
+	}
+	server.Spec.BIOS = append(server.Spec.BIOS, metalv1alpha1.BIOSSettings{Version: version, Settings: desired})
+	return true
+}
+
+// Revert restores the values the Server defined before the settings were written, see Defined, and removes the
+// settings it did not define. Settings which were changed since they were written are kept, the BIOS settings of
+// the version are removed if they are emptied. It reports whether the spec of the Server changed.
+func Revert(server *metalv1alpha1.Server, version string, written, previous map[string]string) bool {
+	changed := false
+	for i := range server.Spec.BIOS {
+		bios := &server.Spec.BIOS[i]
+		if bios.Version != version {
+			continue
+		}
+		for name, value := range written {
+			if current, ok := bios.Settings[name]; !ok || current != value {
+				continue
+			}
+			if value, ok := previous[name]; ok {
+				bios.Settings[name] = value
+			} else {
+				delete(bios.Settings, name)
+			}
+			changed = true
+		}
+	}
+	if changed {
+		server.Spec.BIOS = slices.DeleteFunc(server.Spec.BIOS, func(bios metalv1alpha1.BIOSSettings) bool {
+			return bios.Version == version && len(bios.Settings) == 0
+		})
+	}
+	return changed
+}
+
+// Applied reports whether the Server runs the BIOS version and reports all settings with their values.
+func Applied(server *metalv1alpha1.Server, version string, settings map[string]string) bool {
+	return server.Status.BIOS.Version == version && len(Unapplied(server, settings)) == 0
+}
+
+// Unapplied returns the sorted names of the settings the Server does not report with their values.
+func Unapplied(server *metalv1alpha1.Server, settings map[string]string) []string {
+	var names []string
+	for name, value := range settings {
+		if current, ok := server.Status.BIOS.Settings[name]; !ok || current != value {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RebootNeeded reports whether metal-operator staged BIOS settings of the Server which are applied by its next boot.
+func RebootNeeded(server *metalv1alpha1.Server) bool {
+	return meta.FindStatusCondition(server.Status.Conditions, RebootNeededCondition) != nil
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bios
+
+import (
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("BIOS", func() {
+	var server *metalv1alpha1.Server
+
+	BeforeEach(func() {
+		server = &metalv1alpha1.Server{
+			Status: metalv1alpha1.ServerStatus{
+				BIOS: metalv1alpha1.BIOSSettings{Version: "2.1", Settings: map[string]string{"ProcVirtualization": "Disabled"}},
+			},
+		}
+	})
+
+	It("should write the settings for the version", func() {
+		settings := map[string]string{"ProcVirtualization": "Enabled"}
+		Expect(SetDesired(server, "2.1", settings)).To(BeTrue())
+		Expect(server.Spec.BIOS).To(Equal([]metalv1alpha1.BIOSSettings{{Version: "2.1", Settings: map[string]string{"ProcVirtualization": "Enabled"}}}))
+		Expect(SetDesired(server, "2.1", settings)).To(BeFalse())
+	})
+
+	It("should keep other settings of the Server", func() {
+		server.Spec.BIOS = []metalv1alpha1.BIOSSettings{
+			{Version: "2.0", Settings: map[string]string{"SriovGlobalEnable": "Disabled"}},
+			{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Enabled"}},
+		}
+		Expect(SetDesired(server, "2.1", map[string]string{"ProcVirtualization": "Enabled"})).To(BeTrue())
+		Expect(server.Spec.BIOS).To(Equal([]metalv1alpha1.BIOSSettings{
+			{Version: "2.0", Settings: map[string]string{"SriovGlobalEnable": "Disabled"}},
+			{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Enabled", "ProcVirtualization": "Enabled"}},
+		}))
+	})
+
+	It("should revert the written settings to the ones the Server defined before", func() {
+		server.Spec.BIOS = []metalv1alpha1.BIOSSettings{
+			{Version: "2.0", Settings: map[string]string{"SriovGlobalEnable": "Disabled"}},
+			{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Disabled"}},
+		}
+		written := map[string]string{"SriovGlobalEnable": "Enabled", "ProcVirtualization": "Enabled", "BootMode": "Uefi"}
+		previous := Defined(server, "2.1", written)
+		Expect(previous).To(Equal(map[string]string{"SriovGlobalEnable": "Disabled"}))
+
+		Expect(SetDesired(server, "2.1", written)).To(BeTrue())
+		server.Spec.BIOS[1].Settings["BootMode"] = "Legacy"
+		Expect(Revert(server, "2.1", written, previous)).To(BeTrue())
+		Expect(server.Spec.BIOS).To(Equal([]metalv1alpha1.BIOSSettings{
+			{Version: "2.0", Settings: map[string]string{"SriovGlobalEnable": "Disabled"}},
+			{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Disabled", "BootMode": "Legacy"}},
+		}))
+		Expect(Revert(server, "2.1", written, previous)).To(BeFalse())
+	})
+
+	It("should remove the settings of the version which only held the written settings", func() {
+		written := map[string]string{"ProcVirtualization": "Enabled"}
+		previous := Defined(server, "2.1", written)
+		Expect(previous).To(BeEmpty())
+
+		Expect(SetDesired(server, "2.1", written)).To(BeTrue())
+		Expect(Revert(server, "2.1", written, previous)).To(BeTrue())
+		Expect(server.Spec.BIOS).To(BeEmpty())
+	})
+
+	It("should report whether the Server needs a reboot to apply staged settings", func() {
+		Expect(RebootNeeded(server)).To(BeFalse())
+		server.Status.Conditions = []metav1.Condition{{Type: RebootNeededCondition}}
+		Expect(RebootNeeded(server)).To(BeTrue())
+	})
+
+	It("should report whether the Server applied the settings", func() {
+		settings := map[string]string{"ProcVirtualization": "Enabled"}
+		Expect(Applied(server, "2.1", settings)).To(BeFalse())
+
+		server.Status.BIOS.Settings["ProcVirtualization"] = "Enabled"
+		Expect(Applied(server, "2.1", settings)).To(BeTrue())
+		Expect(Applied(server, "2.2", settings)).To(BeFalse())
+	})
+
+	It("should return the settings the Server does not report with their values", func() {
+		settings := map[string]string{"ProcVirtualization": "Enabled", "SriovGlobalEnable": "Enabled", "BootMode": "Uefi"}
+		server.Status.BIOS.Settings["BootMode"] = "Uefi"
+		Expect(Unapplied(server, settings)).To(Equal([]string{"ProcVirtualization", "SriovGlobalEnable"}))
+	})
+})
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bootstrap handles the bootstrap data Secrets rendered by Cluster API bootstrap providers:
+// it detects the format of the data and substitutes the variables of the infrastructure provider.
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ValueKey is the key of the bootstrap data in a bootstrap data Secret.
+	ValueKey = "value"
+	// FormatKey is the key of the bootstrap data format in a bootstrap data Secret.
+	FormatKey = "format"
+
+	// HostnameVariable is substituted with the name of the IroncoreMetalMachine.
+	HostnameVariable = "METAL_HOSTNAME"
+)
+
+// Format is the format of bootstrap data.
+type Format string
+
+const (
+	// FormatIgnition is an Ignition config, e.g. rendered by CABPK or RKE2.
+	FormatIgnition Format = "ignition"
+	// FormatCloudConfig is a cloud-init cloud-config, e.g. rendered by CABPK, k3s or RKE2.
+	FormatCloudConfig Format = "cloud-config"
+	// FormatTalos is a Talos machine config rendered by CABPT.
+	FormatTalos Format = "talos"
+)
+
+// ErrUnsupportedFormat is returned for bootstrap data in a format which is not supported.
+var ErrUnsupportedFormat = errors.New("unsupported bootstrap data format")
+
+// DetectFormat returns the format of the bootstrap data Secret data. The format key set by the bootstrap
+// provider takes precedence, the data itself is inspected only if the key is missing.
+func DetectFormat(data map[string][]byte) (Format, error) {
+	if format := strings.TrimSpace(string(data[FormatKey])); format != "" {
+		switch f := Format(format); f {
+		case FormatIgnition, FormatCloudConfig, FormatTalos:
+			return f, nil
+		default:
+			return "", fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+		}
+	}
+
+	value := bytes.TrimSpace(data[ValueKey])
+	switch {
+	case len(value) == 0:
+		return "", fmt.Errorf("%w: bootstrap data is empty", ErrUnsupportedFormat)
+	case isIgnition(value):
+		return FormatIgnition, nil
+	case isCloudConfig(value):
+		return FormatCloudConfig, nil
+	case isTalos(value):
+		return FormatTalos, nil
+	default:
+		return "", fmt.Errorf("%w: bootstrap data has no format key and is neither ignition, cloud-config nor a talos machine config", ErrUnsupportedFormat)
+	}
+}
+
+// Substitute replaces the references to the given variables in data with their values. A variable NAME is
+// referenced as ${NAME} or as $${NAME}, which survives the envsubst of clusterctl, either literally or
+// URL encoded as found in data URLs of ignitions.
+func Substitute(data []byte, variables map[string]string) []byte {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var replacements []string
+	for _, name := range names {
+		for _, reference := range []string{"$${" + name + "}", "${" + name + "}"} {
+			replacements = append(replacements,
+				reference, variables[name],
+				url.QueryEscape(reference), url.QueryEscape(variables[name]),
+			)
+		}
+	}
+	return []byte(strings.NewReplacer(replacements...).Replace(string(data)))
+}
+
+func isIgnition(value []byte) bool {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return false
+	}
+	_, ok := doc["ignition"]
+	return ok
+}
+
+func isCloudConfig(value []byte) bool {
+	firstLine, rest, _ := bytes.Cut(value, []byte("\n"))
+	// CABPK renders cloud-configs as jinja template for cloud-init.
+	if strings.HasPrefix(string(firstLine), "## template:") {
+		firstLine, _, _ = bytes.Cut(bytes.TrimSpace(rest), []byte("\n"))
+	}
+	return strings.TrimSpace(string(firstLine)) == "#cloud-config"
+}
+
+func isTalos(value []byte) bool {
+	var doc struct {
+		Version string         `json:"version"`
+		Machine map[string]any `json:"machine"`
+	}
+	if err := yaml.Unmarshal(value, &doc); err != nil {
+		return false
+	}
+	return strings.HasPrefix(doc.Version, "v1alpha") && doc.Machine != nil
+}
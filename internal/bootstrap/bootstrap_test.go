@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootstrap
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DetectFormat", func() {
+	DescribeTable("should detect the format",
+		func(format, value string, expected Format) {
+			data := map[string][]byte{ValueKey: []byte(value)}
+			if format != "" {
+				data[FormatKey] = []byte(format)
+			}
+			Expect(DetectFormat(data)).To(Equal(expected))
+		},
+		Entry("ignition format key", "ignition", `{}`, FormatIgnition),
+		Entry("cloud-config format key", "cloud-config", `#cloud-config`, FormatCloudConfig),
+		Entry("talos format key", "talos", `version: v1alpha1`, FormatTalos),
+		Entry("ignition data", "", `{"ignition":{"version":"3.4.0"}}`, FormatIgnition),
+		Entry("cloud-config data", "", "#cloud-config\nruncmd: []\n", FormatCloudConfig),
+		Entry("jinja cloud-config data", "", "## template: jinja\n#cloud-config\nruncmd: []\n", FormatCloudConfig),
+		Entry("talos data", "", "version: v1alpha1\nmachine:\n  type: controlplane\n", FormatTalos),
+	)
+
+	DescribeTable("should reject unsupported formats",
+		func(format, value string) {
+			data := map[string][]byte{ValueKey: []byte(value)}
+			if format != "" {
+				data[FormatKey] = []byte(format)
+			}
+			_, err := DetectFormat(data)
+			Expect(err).To(MatchError(ErrUnsupportedFormat))
+		},
+		Entry("unknown format key", "mime-multipart", `{}`),
+		Entry("empty data", "", ""),
+		Entry("shell script", "", "#!/bin/sh\necho hello\n"),
+		Entry("JSON without ignition", "", `{"foo":"bar"}`),
+	)
+})
+
+var _ = Describe("Substitute", func() {
+	It("should substitute literal and URL encoded references", func() {
+		data := []byte(`hostnamectl set-hostname $${METAL_HOSTNAME}; echo ${METAL_HOSTNAME} ` +
+			`data:,%24%24%7BMETAL_HOSTNAME%7D %24%7BMETAL_HOSTNAME%7D ${OTHER}`)
+		Expect(string(Substitute(data, map[string]string{HostnameVariable: "machine-1"}))).To(Equal(
+			`hostnamectl set-hostname machine-1; echo machine-1 data:,machine-1 machine-1 ${OTHER}`))
+	})
+})
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+const (
+	// ironcoreMetalClusterSecretField indexes IroncoreMetalClusters by the names of the Secrets they reference.
+	ironcoreMetalClusterSecretField = ".spec.secretRefs"
+	// ironcoreMetalMachineImagePullSecretField indexes IroncoreMetalMachines by the name of their image pull Secret.
+	ironcoreMetalMachineImagePullSecretField = ".spec.imagePullSecretRef.name"
+	// ironcoreMetalMachineUnplacedField indexes the IroncoreMetalMachines which wait for a Server with
+	// "true", see ironcoreMetalMachineUnplaced.
+	ironcoreMetalMachineUnplacedField = ".status.unplaced"
+)
+
+// setupIroncoreMetalMachineIndexes adds the field indexes the IroncoreMetalMachine controller looks up objects by.
+func setupIroncoreMetalMachineIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &infrav1alpha1.IroncoreMetalCluster{}, ironcoreMetalClusterSecretField, ironcoreMetalClusterSecretNames); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &infrav1alpha1.IroncoreMetalMachine{}, ironcoreMetalMachineImagePullSecretField, ironcoreMetalMachineImagePullSecretNames); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &infrav1alpha1.IroncoreMetalMachine{}, ironcoreMetalMachineUnplacedField, ironcoreMetalMachineUnplaced)
+}
+
+// ironcoreMetalClusterSecretNames returns the names of the Secrets the IroncoreMetalCluster references for access
+// users or for pulling images, including the image pull Secrets of its machine defaults.
+func ironcoreMetalClusterSecretNames(obj client.Object) []string {
+	ironcoremetalcluster := obj.(*infrav1alpha1.IroncoreMetalCluster)
+	names := accessSecretNames(ironcoremetalcluster)
+	if ref := ironcoremetalcluster.Spec.ImagePullSecretRef; ref != nil {
+		names.Insert(ref.Name)
+	}
+	if defaults := ironcoremetalcluster.Spec.MachineDefaults; defaults != nil {
+		for _, settings := range []*infrav1alpha1.MachineSettings{defaults.ControlPlane, defaults.Worker} {
+			if settings != nil && settings.ImagePullSecretRef != nil {
+				names.Insert(settings.ImagePullSecretRef.Name)
+			}
+		}
+	}
+	return sets.List(names)
+}
+
+// ironcoreMetalMachineImagePullSecretNames returns the names of the image pull Secrets of the spec and of the
+// effective settings of the IroncoreMetalMachine.
+func ironcoreMetalMachineImagePullSecretNames(obj client.Object) []string {
+	ironcoremetalmachine := obj.(*infrav1alpha1.IroncoreMetalMachine)
+	names := sets.New[string]()
+	if ref := ironcoremetalmachine.Spec.ImagePullSecretRef; ref != nil {
+		names.Insert(ref.Name)
+	}
+	if settings := ironcoremetalmachine.Status.EffectiveSettings; settings != nil && settings.ImagePullSecretRef != nil {
+		names.Insert(settings.ImagePullSecretRef.Name)
+	}
+	return sets.List(names)
+}
+
+// ironcoreMetalMachineUnplaced returns "true" for the IroncoreMetalMachines which are neither deleted nor failed
+// and only wait for a Server, which is marked by the WaitingForServers and Queued reasons of the ServerAvailable
+// condition. Machines blocked by anything else, like their quota, must not hold back the machines queued behind them.
+func ironcoreMetalMachineUnplaced(obj client.Object) []string {
+	ironcoremetalmachine := obj.(*infrav1alpha1.IroncoreMetalMachine)
+	if !ironcoremetalmachine.DeletionTimestamp.IsZero() || ironcoremetalmachine.Status.FailureReason != "" ||
+		!conditions.IsFalse(ironcoremetalmachine, infrav1alpha1.ServerAvailableCondition) {
+		return nil
+	}
+	switch conditions.GetReason(ironcoremetalmachine, infrav1alpha1.ServerAvailableCondition) {
+	case infrav1alpha1.WaitingForServersReason, infrav1alpha1.QueuedReason:
+		return []string{"true"}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+)
+
+// newIndexedFakeClient returns a fake client with the objects and the field indexes of the IroncoreMetalMachine
+// controller.
+func newIndexedFakeClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	Expect(metalv1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(clusterapiv1beta1.AddToScheme(scheme)).To(Succeed())
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithIndex(&infrav1.IroncoreMetalCluster{}, ironcoreMetalClusterSecretField, ironcoreMetalClusterSecretNames).
+		WithIndex(&infrav1.IroncoreMetalMachine{}, ironcoreMetalMachineImagePullSecretField, ironcoreMetalMachineImagePullSecretNames).
+		WithIndex(&infrav1.IroncoreMetalMachine{}, ironcoreMetalMachineUnplacedField, ironcoreMetalMachineUnplaced).
+		Build()
+}
+
+var _ = Describe("secretToIroncoreMetalMachines", func() {
+	newMachine := func(name, cluster string, pullSecret *corev1.LocalObjectReference) *infrav1.IroncoreMetalMachine {
+		return &infrav1.IroncoreMetalMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: cluster}},
+			Spec:       infrav1.IroncoreMetalMachineSpec{ImagePullSecretRef: pullSecret},
+		}
+	}
+	newCluster := func(name string, spec infrav1.IroncoreMetalClusterSpec) *infrav1.IroncoreMetalCluster {
+		return &infrav1.IroncoreMetalCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: name}},
+			Spec:       spec,
+		}
+	}
+
+	It("should only enqueue the IroncoreMetalMachines which use the Secret", func(ctx SpecContext) {
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(
+			newCluster("defaults", infrav1.IroncoreMetalClusterSpec{MachineDefaults: &infrav1.MachineDefaults{
+				Worker: &infrav1.MachineSettings{ImagePullSecretRef: &corev1.LocalObjectReference{Name: "pull"}},
+			}}),
+			newCluster("other", infrav1.IroncoreMetalClusterSpec{}),
+			newMachine("defaults-0", "defaults", nil),
+			newMachine("other-0", "other", nil),
+			newMachine("other-1", "other", &corev1.LocalObjectReference{Name: "pull"}),
+		)}
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pull"}}
+		Expect(reconciler.secretToIroncoreMetalMachines(ctx, secret)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "defaults-0"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "other-1"}},
+		))
+
+		unused := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unused"}}
+		Expect(reconciler.secretToIroncoreMetalMachines(ctx, unused)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("imageCatalogToIroncoreMetalMachines", func() {
+	It("should enqueue the IroncoreMetalMachines which inherit the catalog before they are reconciled", func(ctx SpecContext) {
+		catalogRef := &corev1.LocalObjectReference{Name: "catalog"}
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(
+			&infrav1.IroncoreMetalCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+				Spec: infrav1.IroncoreMetalClusterSpec{MachineDefaults: &infrav1.MachineDefaults{
+					Worker: &infrav1.MachineSettings{ImageCatalogRef: catalogRef},
+				}},
+			},
+			&infrav1.IroncoreMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "new", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+			},
+			&infrav1.IroncoreMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "reconciled", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+				Status:     infrav1.IroncoreMetalMachineStatus{EffectiveSettings: &infrav1.MachineSettings{ImageCatalogRef: catalogRef}},
+			},
+			&infrav1.IroncoreMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+				Spec:       infrav1.IroncoreMetalMachineSpec{Image: "registry.example.com/os:latest"},
+			},
+		)}
+
+		catalog := &infrav1.IroncoreMetalImageCatalog{ObjectMeta: metav1.ObjectMeta{Name: "catalog"}}
+		Expect(reconciler.imageCatalogToIroncoreMetalMachines(ctx, catalog)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "new"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "reconciled"}},
+		))
+	})
+})
+
+var _ = Describe("machineClassToIroncoreMetalMachines", func() {
+	It("should only enqueue the IroncoreMetalMachines of the class", func(ctx SpecContext) {
+		newMachine := func(namespace, name, class string) *infrav1.IroncoreMetalMachine {
+			return &infrav1.IroncoreMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Spec:       infrav1.IroncoreMetalMachineSpec{Class: class},
+			}
+		}
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(
+			newMachine("default", "large-0", "large"),
+			newMachine("other", "large-1", "large"),
+			newMachine("default", "small-0", "small"),
+			newMachine("default", "none", ""),
+		)}
+
+		class := &infrav1.IroncoreMetalMachineClass{ObjectMeta: metav1.ObjectMeta{Name: "large"}}
+		Expect(reconciler.machineClassToIroncoreMetalMachines(ctx, class)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "large-0"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "other", Name: "large-1"}},
+		))
+	})
+})
+
+var _ = Describe("waitingCandidates", func() {
+	newMachine := func(name string, serverAvailable *clusterapiv1beta1.Condition) *infrav1.IroncoreMetalMachine {
+		machine := &infrav1.IroncoreMetalMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+		}
+		if serverAvailable != nil {
+			machine.Status.Conditions = clusterapiv1beta1.Conditions{*serverAvailable}
+		}
+		return machine
+	}
+
+	It("should return the IroncoreMetalMachines without ServerClaim which only wait for Servers", func(ctx SpecContext) {
+		placed := &clusterapiv1beta1.Condition{Type: infrav1.ServerAvailableCondition, Status: corev1.ConditionTrue}
+		waiting := &clusterapiv1beta1.Condition{Type: infrav1.ServerAvailableCondition, Status: corev1.ConditionFalse, Reason: infrav1.WaitingForServersReason}
+		queued := &clusterapiv1beta1.Condition{Type: infrav1.ServerAvailableCondition, Status: corev1.ConditionFalse, Reason: infrav1.QueuedReason}
+		quota := &clusterapiv1beta1.Condition{Type: infrav1.ServerAvailableCondition, Status: corev1.ConditionFalse, Reason: infrav1.QuotaExceededReason}
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(
+			&infrav1.IroncoreMetalCluster{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: "cluster", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"},
+			}},
+			newMachine("new", nil),
+			newMachine("waiting", waiting),
+			newMachine("queued", queued),
+			newMachine("quota", quota),
+			newMachine("placed", placed),
+			newMachine("claimed", waiting),
+		)}
+
+		claims := []metalv1alpha1.ServerClaim{{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "claimed"}}}
+		candidates, err := reconciler.waitingCandidates(ctx, claims)
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, candidate := range candidates {
+			names = append(names, candidate.Name.Name)
+		}
+		Expect(names).To(ConsistOf("waiting", "queued"))
+	})
+
+	It("should not queue machines blocked by their quota ahead of machines with lower priority", func(ctx SpecContext) {
+		ironcoremetalcluster := &infrav1.IroncoreMetalCluster{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: "cluster", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"},
+		}}
+		blocked := newMachine("blocked", &clusterapiv1beta1.Condition{
+			Type: infrav1.ServerAvailableCondition, Status: corev1.ConditionFalse, Reason: infrav1.QuotaExceededReason,
+		})
+		blocked.Spec.Priority = ptr.To[int32](100)
+		machine := newMachine("machine", nil)
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(ironcoremetalcluster, blocked, machine)}
+		servers := []metalv1alpha1.Server{{
+			ObjectMeta: metav1.ObjectMeta{Name: "server"},
+			Status:     metalv1alpha1.ServerStatus{State: metalv1alpha1.ServerStateAvailable},
+		}}
+		machineScope := &scope.MachineScope{IroncoreMetalCluster: ironcoremetalcluster, IroncoreMetalMachine: machine}
+
+		Expect(reconciler.queuePosition(ctx, machineScope, placement.Request{}, servers, sets.New[string](), nil)).To(Equal(1))
+
+		blocked.Status.Conditions[0].Reason = infrav1.WaitingForServersReason
+		Expect(reconciler.Update(ctx, blocked)).To(Succeed())
+		Expect(reconciler.queuePosition(ctx, machineScope, placement.Request{}, servers, sets.New[string](), nil)).To(Equal(2))
+	})
+})
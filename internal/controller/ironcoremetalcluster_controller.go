@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
 	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -25,6 +26,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
 )
 
 // IroncoreMetalClusterReconciler reconciles a IroncoreMetalCluster object
@@ -37,6 +39,8 @@ type IroncoreMetalClusterReconciler struct {
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=serverclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=servers,verbs=get;list;watch
 
 func (r *IroncoreMetalClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -125,13 +129,17 @@ func (r *IroncoreMetalClusterReconciler) reconcileDelete(ctx context.Context, cl
 	return ctrl.Result{}, nil
 }
 
-//nolint:unparam
-func (r *IroncoreMetalClusterReconciler) reconcileNormal(_ context.Context, clusterScope *scope.ClusterScope) (reconcile.Result, error) {
+func (r *IroncoreMetalClusterReconciler) reconcileNormal(ctx context.Context, clusterScope *scope.ClusterScope) (reconcile.Result, error) {
 	clusterScope.Logger.Info("Reconciling IroncoreMetalCluster")
 
 	// If the IroncoreMetalCluster doesn't have our finalizer, add it.
 	ctrlutil.AddFinalizer(clusterScope.IroncoreMetalCluster, infrav1.ClusterFinalizer)
 
+	if err := r.reconcileQuotaUsage(ctx, clusterScope); err != nil {
+		clusterScope.Error(err, "failed to compute quota usage")
+		return ctrl.Result{}, err
+	}
+
 	conditions.MarkTrue(clusterScope.IroncoreMetalCluster, infrav1.IroncoreMetalClusterReady)
 
 	clusterScope.IroncoreMetalCluster.Status.Ready = true
@@ -139,6 +147,35 @@ func (r *IroncoreMetalClusterReconciler) reconcileNormal(_ context.Context, clus
 	return ctrl.Result{}, nil
 }
 
+// reconcileQuotaUsage reports the number of Servers the IroncoreMetalMachines of the cluster claim in the status if
+// the cluster has a quota.
+func (r *IroncoreMetalClusterReconciler) reconcileQuotaUsage(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	quota := clusterScope.IroncoreMetalCluster.Spec.Quota
+	if quota == nil {
+		clusterScope.IroncoreMetalCluster.Status.QuotaUsage = nil
+		return nil
+	}
+
+	serverList := &metalv1alpha1.ServerList{}
+	if err := r.List(ctx, serverList); err != nil {
+		return fmt.Errorf("failed to list Servers: %w", err)
+	}
+	claimList := &metalv1alpha1.ServerClaimList{}
+	if err := r.List(ctx, claimList, client.InNamespace(clusterScope.Namespace())); err != nil {
+		return fmt.Errorf("failed to list ServerClaims: %w", err)
+	}
+	claims, err := clusterServerClaims(ctx, r.Client, clusterScope.Namespace(), clusterScope.Name(), claimList.Items)
+	if err != nil {
+		return err
+	}
+	usage, err := placement.QuotaUsage(quota, claims, serverList.Items)
+	if err != nil {
+		return err
+	}
+	clusterScope.IroncoreMetalCluster.Status.QuotaUsage = &usage
+	return nil
+}
+
 func (r *IroncoreMetalClusterReconciler) listIroncoreMetalMachinesForCluster(ctx context.Context, clusterScope *scope.ClusterScope) ([]infrav1.IroncoreMetalMachine, error) {
 	var machineList infrav1.IroncoreMetalMachineList
 	err := r.List(ctx, &machineList, client.InNamespace(clusterScope.Namespace()), client.MatchingLabels{
@@ -160,5 +197,36 @@ func (r *IroncoreMetalClusterReconciler) SetupWithManager(ctx context.Context, m
 			&clusterv1.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(ctx, infrav1.GroupVersion.WithKind("IroncoreMetalCluster"), mgr.GetClient(), &infrav1.IroncoreMetalCluster{})),
 		).
+		Watches(
+			&metalv1alpha1.ServerClaim{},
+			handler.EnqueueRequestsFromMapFunc(r.serverClaimToIroncoreMetalCluster),
+		).
 		Complete(r)
 }
+
+// serverClaimToIroncoreMetalCluster enqueues the IroncoreMetalCluster of the ServerClaim, so that its quota usage is
+// updated. The cluster is taken from the label of the ServerClaim, which outlives its IroncoreMetalMachine. ServerClaims
+// created before they were labeled are mapped by their IroncoreMetalMachine.
+func (r *IroncoreMetalClusterReconciler) serverClaimToIroncoreMetalCluster(ctx context.Context, obj client.Object) []reconcile.Request {
+	clusterName, ok := obj.GetLabels()[clusterv1.ClusterNameLabel]
+	if !ok {
+		machine := &infrav1.IroncoreMetalMachine{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), machine); err != nil {
+			return nil
+		}
+		if clusterName, ok = machine.Labels[clusterv1.ClusterNameLabel]; !ok {
+			return nil
+		}
+	}
+
+	clusterList := &infrav1.IroncoreMetalClusterList{}
+	if err := r.List(ctx, clusterList, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalClusters", "Cluster", clusterName)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(clusterList.Items))
+	for i := range clusterList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterList.Items[i])})
+	}
+	return requests
+}
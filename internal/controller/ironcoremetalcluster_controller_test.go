@@ -6,10 +6,12 @@ package controller
 import (
 	"context"
 
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -69,3 +71,20 @@ var _ = Describe("IroncoreMetalCluster Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("serverClaimToIroncoreMetalCluster", func() {
+	It("should enqueue the IroncoreMetalCluster of a ServerClaim whose IroncoreMetalMachine is gone", func(ctx SpecContext) {
+		reconciler := &IroncoreMetalClusterReconciler{Client: newIndexedFakeClient(&infrav1.IroncoreMetalCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+		})}
+
+		claim := &metalv1alpha1.ServerClaim{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "machine",
+			Labels:    map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"},
+		}}
+		Expect(reconciler.serverClaimToIroncoreMetalCluster(ctx, claim)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "cluster"}},
+		))
+	})
+})
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/bios"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+const (
+	// biosSettingsTimeout is the time a Server has to apply the BIOS settings after they were written.
+	biosSettingsTimeout = 30 * time.Minute
+)
+
+var (
+	// errBIOSVersionMismatch is returned if the bound Server reports another BIOS version than the one the BIOS
+	// settings of an IroncoreMetalMachine are defined for.
+	errBIOSVersionMismatch = errors.New("BIOS version mismatch")
+	// errBIOSSettingsNotApplied is returned if the bound Server does not report the BIOS settings of an
+	// IroncoreMetalMachine within biosSettingsTimeout after they were written.
+	errBIOSSettingsNotApplied = errors.New("BIOS settings not applied")
+)
+
+// reconcileBIOSSettings writes the BIOS settings of the IroncoreMetalMachine to the bound Server and reports whether
+// they are written and whether the Server applied them, both are true if the IroncoreMetalMachine has no BIOS
+// settings. The written settings are recorded in the status, so that
+// they are reverted when the IroncoreMetalMachine is deleted. metal-operator stages the settings and adds its Reboot
+// needed condition to a Server, which is restarted once if it is powered on already. An errBIOSVersionMismatch error
+// is returned if the Server reports another BIOS version, an errBIOSSettingsNotApplied error if it does not report
+// the settings within biosSettingsTimeout.
+func (r *IroncoreMetalMachineReconciler) reconcileBIOSSettings(ctx context.Context, machineScope *scope.MachineScope, server *metalv1alpha1.Server) (bool, bool, error) {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	settings := machineScope.Settings.BIOSSettings
+	if settings == nil || len(settings.Settings) == 0 {
+		conditions.Delete(ironcoremetalmachine, infrav1alpha1.BIOSSettingsAppliedCondition)
+		return true, true, nil
+	}
+	if server == nil {
+		conditions.MarkFalse(ironcoremetalmachine, infrav1alpha1.BIOSSettingsAppliedCondition, infrav1alpha1.BIOSSettingsPendingReason, clusterapiv1beta1.ConditionSeverityInfo,
+			"the BIOS settings are written to the Server once the ServerClaim is bound")
+		return false, false, nil
+	}
+	if current := server.Status.BIOS.Version; current != "" && current != settings.Version {
+		return false, false, fmt.Errorf("%w: the BIOS settings are defined for version %s, Server %s runs version %s", errBIOSVersionMismatch, settings.Version, server.Name, current)
+	}
+
+	if record := ironcoremetalmachine.Status.BIOS; record != nil && record.Server != server.Name {
+		if err := r.revertBIOSSettings(ctx, machineScope); err != nil {
+			return false, false, err
+		}
+	}
+	if ironcoremetalmachine.Status.BIOS == nil {
+		ironcoremetalmachine.Status.BIOS = &infrav1alpha1.BIOSStatus{
+			Server:   server.Name,
+			Version:  settings.Version,
+			Settings: maps.Clone(settings.Settings),
+			Previous: bios.Defined(server, settings.Version, settings.Settings),
+		}
+	}
+	record := ironcoremetalmachine.Status.BIOS
+	if record.WrittenAt == nil {
+		record.WrittenAt = ptr.To(metav1.Now())
+	}
+
+	base := server.DeepCopy()
+	if bios.SetDesired(server, settings.Version, settings.Settings) {
+		if err := r.Patch(ctx, server, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			return false, false, fmt.Errorf("failed to patch BIOS settings of Server %s: %w", server.Name, err)
+		}
+		machineScope.Info("Patched BIOS settings of Server", "Server", server.Name, "Version", settings.Version)
+	}
+
+	if bios.Applied(server, settings.Version, settings.Settings) {
+		conditions.MarkTrue(ironcoremetalmachine, infrav1alpha1.BIOSSettingsAppliedCondition)
+		return true, true, nil
+	}
+	if !record.Restarted && server.Status.PowerState == metalv1alpha1.ServerOnPowerState && bios.RebootNeeded(server) {
+		base := server.DeepCopy()
+		metav1.SetMetaDataAnnotation(&server.ObjectMeta, metalv1alpha1.OperationAnnotation, bios.RestartOperation)
+		if err := r.Patch(ctx, server, client.MergeFrom(base)); err != nil {
+			return true, false, fmt.Errorf("failed to restart Server %s: %w", server.Name, err)
+		}
+		record.Restarted = true
+		machineScope.Info("Restarting Server to apply the BIOS settings", "Server", server.Name)
+	}
+	if time.Since(record.WrittenAt.Time) > biosSettingsTimeout {
+		return true, false, fmt.Errorf("%w: Server %s did not report the BIOS settings %s for version %s within %s",
+			errBIOSSettingsNotApplied, server.Name, strings.Join(bios.Unapplied(server, settings.Settings), ", "), settings.Version, biosSettingsTimeout)
+	}
+	conditions.MarkFalse(ironcoremetalmachine, infrav1alpha1.BIOSSettingsAppliedCondition, infrav1alpha1.BIOSSettingsPendingReason, clusterapiv1beta1.ConditionSeverityInfo,
+		"waiting for Server %s to apply the BIOS settings", server.Name)
+	return true, false, nil
+}
+
+// revertBIOSSettings reverts the BIOS settings recorded in the status of the IroncoreMetalMachine on their Server
+// and removes the record.
+func (r *IroncoreMetalMachineReconciler) revertBIOSSettings(ctx context.Context, machineScope *scope.MachineScope) error {
+	record := machineScope.IroncoreMetalMachine.Status.BIOS
+	if record == nil {
+		return nil
+	}
+	server := &metalv1alpha1.Server{}
+	if err := r.Get(ctx, client.ObjectKey{Name: record.Server}, server); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get Server %s: %w", record.Server, err)
+	} else if err == nil {
+		base := server.DeepCopy()
+		if bios.Revert(server, record.Version, record.Settings, record.Previous) {
+			if err := r.Patch(ctx, server, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+				return fmt.Errorf("failed to revert BIOS settings of Server %s: %w", server.Name, err)
+			}
+			machineScope.Info("Reverted BIOS settings of Server", "Server", server.Name, "Version", record.Version)
+		}
+	}
+	machineScope.IroncoreMetalMachine.Status.BIOS = nil
+	return nil
+}
+
+// biosVersion returns the BIOS version the BIOS settings are defined for, or an empty string if there are none.
+func biosVersion(settings infrav1alpha1.MachineSettings) string {
+	if settings.BIOSSettings == nil || len(settings.BIOSSettings.Settings) == 0 {
+		return ""
+	}
+	return settings.BIOSSettings.Version
+}
+
+// biosFailureReason returns the terminal failure reason of an error applying the BIOS settings, or an empty string
+// if the error is transient.
+func biosFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errBIOSVersionMismatch):
+		return infrav1alpha1.BIOSVersionMismatchReason
+	case errors.Is(err, errBIOSSettingsNotApplied):
+		return infrav1alpha1.BIOSSettingsNotAppliedReason
+	default:
+		return ""
+	}
+}
@@ -6,9 +6,10 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry"
 	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
 	"github.com/ironcore-dev/controller-utils/clientutils"
 	"github.com/pkg/errors"
@@ -21,6 +22,7 @@ import (
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -38,21 +40,45 @@ import (
 type IroncoreMetalMachineReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// IgnitionPartsURL is the base URL under which servers reach the ignition part server.
+	// Ignitions exceeding the Secret size limit can only be split into parts if it is set.
+	IgnitionPartsURL string
+	// IgnitionPartsCA is the PEM encoded CA of the serving certificate of the ignition part server. Ignitions
+	// with parts trust it in addition to the system CAs.
+	IgnitionPartsCA []byte
+
+	// Registry is the client used to verify the signatures of OS images.
+	Registry *registry.Client
+
+	// APIReader reads ServerClaims and Servers from the API server instead of the cache before a ServerClaim is
+	// pinned, so that Servers claimed by recently created ServerClaims are not chosen again.
+	APIReader client.Reader
+
+	// insufficientDeployments are the MachineDeployments a warning event about too few matching Servers was emitted
+	// for, mapped to the number of matching Servers, so that the event is only emitted when the number changes.
+	insufficientDeployments sync.Map
 }
 
 const (
 	IroncoreMetalMachineFinalizer = "infrastructure.cluster.x-k8s.io/ironcoremetalmachine"
 	DefaultIgnitionSecretKeyName  = "ignition"
+	DefaultMetadataSecretKeyName  = "metadata"
 )
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalmachines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalmachines/finalizers,verbs=update
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalimagecatalogs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalserverpools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalmachineclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinesets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=serverclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=servers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=bmcs,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
@@ -160,12 +186,39 @@ func (r *IroncoreMetalMachineReconciler) Reconcile(ctx context.Context, req ctrl
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *IroncoreMetalMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := setupIroncoreMetalMachineIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1alpha1.IroncoreMetalMachine{}).
 		Watches(
 			&clusterapiv1beta1.Machine{},
 			handler.EnqueueRequestsFromMapFunc(util.MachineToInfrastructureMapFunc(infrav1alpha1.GroupVersion.WithKind("IroncoreMetalMachine"))),
 		).
+		Watches(
+			&infrav1alpha1.IroncoreMetalCluster{},
+			handler.EnqueueRequestsFromMapFunc(r.ironcoreMetalClusterToIroncoreMetalMachines),
+		).
+		Watches(
+			&infrav1alpha1.IroncoreMetalImageCatalog{},
+			handler.EnqueueRequestsFromMapFunc(r.imageCatalogToIroncoreMetalMachines),
+		).
+		Watches(
+			&infrav1alpha1.IroncoreMetalServerPool{},
+			handler.EnqueueRequestsFromMapFunc(r.serverPoolToIroncoreMetalMachines),
+		).
+		Watches(
+			&infrav1alpha1.IroncoreMetalMachineClass{},
+			handler.EnqueueRequestsFromMapFunc(r.machineClassToIroncoreMetalMachines),
+		).
+		Watches(
+			&metalv1alpha1.Server{},
+			handler.EnqueueRequestsFromMapFunc(serverToIroncoreMetalMachine),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.secretToIroncoreMetalMachines),
+		).
 		Complete(r)
 }
 
@@ -174,6 +227,11 @@ func (r *IroncoreMetalMachineReconciler) reconcileDelete(ctx context.Context, ma
 
 	// insert ServerClaim deletion logic here
 
+	if err := r.revertBIOSSettings(ctx, machineScope); err != nil {
+		machineScope.Error(err, "failed to revert BIOS settings")
+		return ctrl.Result{}, err
+	}
+
 	if modified, err := clientutils.PatchEnsureNoFinalizer(ctx, r.Client, machineScope.IroncoreMetalMachine, IroncoreMetalMachineFinalizer); !apierrors.IsNotFound(err) || modified {
 		return ctrl.Result{}, err
 	}
@@ -220,19 +278,171 @@ func (r *IroncoreMetalMachineReconciler) reconcileNormal(ctx context.Context, ma
 		return ctrl.Result{}, err
 	}
 
+	// Fetch the Server of an already bound ServerClaim, so that its metadata can be rendered and the image of its
+	// architecture can be chosen.
+	server, err := r.getBoundServer(ctx, machineScope.IroncoreMetalMachine)
+	if err != nil {
+		machineScope.Error(err, "failed to get bound Server")
+		return ctrl.Result{}, err
+	}
+
+	class, err := r.getMachineClass(ctx, machineScope.IroncoreMetalMachine)
+	if errors.Is(err, errMachineClassNotFound) {
+		machineScope.Error(err, "IroncoreMetalMachineClass can not be used")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.MachineClassUpToDateCondition, infrav1alpha1.MachineClassNotFoundReason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		machineScope.Error(err, "failed to get IroncoreMetalMachineClass")
+		return ctrl.Result{}, err
+	}
+	machineScope.SetMachineClass(class)
+	if err := reconcileMachineClassHash(machineScope); err != nil {
+		machineScope.Error(err, "failed to hash IroncoreMetalMachineClass")
+		return ctrl.Result{}, err
+	}
+
+	reconcileRAIDSupport(machineScope.IroncoreMetalMachine)
+
+	// Record the settings inherited from the class and the IroncoreMetalCluster. They are kept once the ServerClaim
+	// exists, see MachineScope.SetMachineClass.
+	machineScope.IroncoreMetalMachine.Status.EffectiveSettings = machineScope.Settings.DeepCopy()
+
+	if err := r.reconcileFirmwareCompliance(ctx, machineScope, server); err != nil {
+		machineScope.Error(err, "failed to check firmware compliance")
+		return ctrl.Result{}, err
+	}
+
+	bootImage, ok, err := r.reconcileImage(ctx, machineScope, server, server != nil)
+	if err != nil || !ok {
+		return ctrl.Result{}, err
+	}
+
+	machineScope.Info("Creating MetadataSecret", "Secret", machineScope.IroncoreMetalMachine.Name)
+	metadataSecret, err := r.applyMetadataSecret(ctx, machineScope.Logger, machineScope, server)
+	if err != nil {
+		machineScope.Error(err, "failed to create or patch metadata secret")
+		return ctrl.Result{}, err
+	}
+	machineScope.IroncoreMetalMachine.Status.MetadataSecretRef = &corev1.LocalObjectReference{Name: metadataSecret.Name}
+
 	machineScope.Info("Creating IgnitionSecret", "Secret", machineScope.IroncoreMetalMachine.Name)
-	ignitionSecret, err := r.applyIgnitionSecret(ctx, machineScope.Logger, machineScope.IroncoreMetalMachine, bootstrapSecret)
+	ignitionSecret, err := r.applyIgnitionSecret(ctx, machineScope.Logger, machineScope, bootstrapSecret, metadataSecret)
+	if reason := bootstrapFailureReason(err); reason != "" {
+		machineScope.Error(err, "bootstrap data can not be rendered")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.IgnitionReadyCondition, reason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		machineScope.SetFailureReason(reason)
+		machineScope.SetFailureMessage(err)
+		return ctrl.Result{}, nil
+	}
+	if errors.Is(err, errInvalidTrustedCABundle) {
+		// Not terminal, the IroncoreMetalCluster watch picks up the corrected bundle.
+		machineScope.Error(err, "trusted CA bundle is invalid")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.IgnitionReadyCondition, infrav1alpha1.InvalidTrustedCABundleReason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		machineScope.Error(err, "failed to create or patch ignition secret")
 		return ctrl.Result{}, err
 	}
 
+	biosWritten, biosApplied, err := r.reconcileBIOSSettings(ctx, machineScope, server)
+	if reason := biosFailureReason(err); reason != "" {
+		machineScope.Error(err, "BIOS settings can not be applied")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.BIOSSettingsAppliedCondition, reason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		machineScope.SetFailureReason(reason)
+		machineScope.SetFailureMessage(err)
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		machineScope.Error(err, "failed to apply BIOS settings")
+		return ctrl.Result{}, err
+	}
+
+	// The server must not boot before the ignition carries its metadata document.
+	power := metalv1alpha1.PowerOn
+	if embedsMetadata(machineScope.Settings) && server == nil {
+		power = metalv1alpha1.PowerOff
+	}
+	// Nor before its BIOS settings are written, which metal-operator stages to be applied by the next boot.
+	if !biosWritten && !machineScope.IroncoreMetalMachine.Status.Ready {
+		power = metalv1alpha1.PowerOff
+	}
+
+	// The pool only restricts the Servers which can be claimed, a bound Server stays with the IroncoreMetalMachine.
+	var pool *infrav1alpha1.IroncoreMetalServerPool
+	if server == nil {
+		pool, err = r.getServerPool(ctx, machineScope)
+		if reason := serverPoolFailureReason(err); reason != "" {
+			machineScope.Error(err, "IroncoreMetalServerPool can not be used")
+			conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ServerAvailableCondition, reason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+			return ctrl.Result{}, nil
+		}
+		if err != nil {
+			machineScope.Error(err, "failed to get IroncoreMetalServerPool")
+			return ctrl.Result{}, err
+		}
+	}
+
+	serverSelector := claimServerSelector(machineScope.Settings, machineScope.IroncoreMetalCluster.Spec.ServerSelector, pool)
+	serverRef, err := r.placeServerClaim(ctx, machineScope, serverSelector)
+	if errors.Is(err, errQuotaExceeded) {
+		machineScope.Info("Waiting for the quota of the cluster to allow another Server", "Reason", err.Error())
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ServerAvailableCondition, infrav1alpha1.QuotaExceededReason, clusterapiv1beta1.ConditionSeverityWarning, "%s", err.Error())
+		conditions.Set(machineScope.IroncoreMetalMachine, &clusterapiv1beta1.Condition{
+			Type:     infrav1alpha1.QuotaExceededCondition,
+			Status:   corev1.ConditionTrue,
+			Severity: clusterapiv1beta1.ConditionSeverityWarning,
+			Reason:   infrav1alpha1.QuotaExceededReason,
+			Message:  err.Error(),
+		})
+		return ctrl.Result{RequeueAfter: infrav1alpha1.DefaultReconcilerRequeue}, nil
+	}
+	conditions.Delete(machineScope.IroncoreMetalMachine, infrav1alpha1.QuotaExceededCondition)
+	if errors.Is(err, errNoMatchingServer) {
+		machineScope.Info("Waiting for a Server matching the IroncoreMetalMachine", "Reason", err.Error())
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ServerAvailableCondition, infrav1alpha1.NoMatchingServerReason, clusterapiv1beta1.ConditionSeverityWarning, "%s", err.Error())
+		return ctrl.Result{RequeueAfter: infrav1alpha1.DefaultReconcilerRequeue}, nil
+	}
+	if errors.Is(err, errWaitingForServers) {
+		machineScope.Info("Waiting for an available Server matching the IroncoreMetalMachine", "Reason", err.Error())
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ServerAvailableCondition, infrav1alpha1.WaitingForServersReason, clusterapiv1beta1.ConditionSeverityWarning, "%s", err.Error())
+		return ctrl.Result{RequeueAfter: infrav1alpha1.DefaultReconcilerRequeue}, nil
+	}
+	if errors.Is(err, errQueued) {
+		machineScope.Info("Waiting for IroncoreMetalMachines ahead in the queue", "Reason", err.Error())
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ServerAvailableCondition, infrav1alpha1.QueuedReason, clusterapiv1beta1.ConditionSeverityInfo, "%s", err.Error())
+		return ctrl.Result{RequeueAfter: infrav1alpha1.DefaultReconcilerRequeue}, nil
+	}
+	if err != nil {
+		machineScope.Error(err, "failed to place ServerClaim")
+		return ctrl.Result{}, err
+	}
+
+	// metal-operator freezes the image into the boot configuration when the ServerClaim is bound, hence the image of
+	// the architecture of the Server has to be chosen before.
+	if bootImage == "" && server == nil && serverRef != nil {
+		target := &metalv1alpha1.Server{}
+		if err := r.Get(ctx, client.ObjectKey{Name: serverRef.Name}, target); err != nil {
+			machineScope.Error(err, "failed to get the Server the ServerClaim is pinned to")
+			return ctrl.Result{}, err
+		}
+		if bootImage, ok, err = r.reconcileImage(ctx, machineScope, target, false); err != nil || !ok {
+			return ctrl.Result{}, err
+		}
+	}
+	if bootImage == "" && server == nil {
+		power = metalv1alpha1.PowerOff
+	}
+
 	machineScope.Info("Creating ServerClaim", "ServerClaim", machineScope.IroncoreMetalMachine.Name)
-	serverClaim, err := r.applyServerClaim(ctx, machineScope.Logger, machineScope.IroncoreMetalMachine, ignitionSecret)
+	serverClaim, err := r.applyServerClaim(ctx, machineScope.Logger, machineScope.IroncoreMetalMachine, serverSelector, ignitionSecret, serverRef, bootImage, power)
 	if err != nil {
 		machineScope.Error(err, "failed to create or patch ServerClaim")
 		return ctrl.Result{}, err
 	}
+	// The Server is only available to the IroncoreMetalMachine once its ServerClaim exists.
+	conditions.MarkTrue(machineScope.IroncoreMetalMachine, infrav1alpha1.ServerAvailableCondition)
 
 	bound, _ := r.ensureServerClaimBound(ctx, serverClaim)
 	if !bound {
@@ -242,6 +452,16 @@ func (r *IroncoreMetalMachineReconciler) reconcileNormal(ctx context.Context, ma
 		}, nil
 	}
 
+	if server == nil {
+		machineScope.Info("ServerClaim got bound, rendering metadata of the Server")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if !biosApplied && !machineScope.IroncoreMetalMachine.Status.Ready {
+		machineScope.Info("Waiting for the Server to apply the BIOS settings", "Server", server.Name)
+		return ctrl.Result{RequeueAfter: infrav1alpha1.DefaultReconcilerRequeue}, nil
+	}
+
 	machineScope.Info("Patching ProviderID in IroncoreMetalMachine")
 	if err := r.patchIroncoreMetalMachineProviderID(ctx, machineScope.Logger, machineScope.IroncoreMetalMachine, serverClaim); err != nil {
 		machineScope.Error(err, "failed to patch the IroncoreMetalMachine with providerid")
@@ -251,41 +471,11 @@ func (r *IroncoreMetalMachineReconciler) reconcileNormal(ctx context.Context, ma
 	machineScope.SetReady()
 	machineScope.Logger.Info("IroncoreMetalMachine is ready")
 
-	return reconcile.Result{}, nil
+	// Re-render the ignition without the break-glass user once it expired.
+	return reconcile.Result{RequeueAfter: breakGlassRequeueAfter(machineScope.IroncoreMetalCluster)}, nil
 }
 
-func (r *IroncoreMetalMachineReconciler) applyIgnitionSecret(ctx context.Context, log *logr.Logger, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, capidatasecret *corev1.Secret) (*corev1.Secret, error) {
-	dataSecret := capidatasecret
-	findAndReplaceIgnition(ironcoremetalmachine, dataSecret)
-
-	secretObj := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("ignition-%s", capidatasecret.Name),
-			Namespace: capidatasecret.Namespace,
-		},
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Secret",
-			APIVersion: corev1.SchemeGroupVersion.String(),
-		},
-		Data: map[string][]byte{
-			DefaultIgnitionSecretKeyName: dataSecret.Data["value"],
-		},
-	}
-
-	if err := controllerutil.SetControllerReference(capidatasecret, secretObj, r.Client.Scheme()); err != nil {
-		return nil, fmt.Errorf("failed to set ControllerReference: %w", err)
-	}
-
-	opResult, err := controllerutil.CreateOrPatch(ctx, r.Client, secretObj, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create or patch the IgnitionSecret: %w", err)
-	}
-	log.Info("Created or Patched IgnitionSecret", "IgnitionSecret", secretObj.Name, "Operation", opResult)
-
-	return secretObj, nil
-}
-
-func (r *IroncoreMetalMachineReconciler) applyServerClaim(ctx context.Context, log *logr.Logger, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, ignitionsecret *corev1.Secret) (*metalv1alpha1.ServerClaim, error) {
+func (r *IroncoreMetalMachineReconciler) applyServerClaim(ctx context.Context, log *logr.Logger, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, serverSelector *metav1.LabelSelector, ignitionsecret *corev1.Secret, serverRef *corev1.LocalObjectReference, image string, power metalv1alpha1.Power) (*metalv1alpha1.ServerClaim, error) {
 	serverClaimObj := &metalv1alpha1.ServerClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ironcoremetalmachine.Name,
@@ -295,21 +485,29 @@ func (r *IroncoreMetalMachineReconciler) applyServerClaim(ctx context.Context, l
 			APIVersion: metalv1alpha1.GroupVersion.String(),
 			Kind:       "ServerClaim",
 		},
-		Spec: metalv1alpha1.ServerClaimSpec{
-			Power: metalv1alpha1.PowerOn,
-			IgnitionSecretRef: &corev1.LocalObjectReference{
-				Name: ignitionsecret.Name,
-			},
-			Image:          ironcoremetalmachine.Spec.Image,
-			ServerSelector: ironcoremetalmachine.Spec.ServerSelector,
-		},
 	}
 
-	if err := controllerutil.SetControllerReference(ironcoremetalmachine, serverClaimObj, r.Client.Scheme()); err != nil {
-		return nil, fmt.Errorf("failed to set ControllerReference: %w", err)
-	}
-
-	opResult, err := controllerutil.CreateOrPatch(ctx, r.Client, serverClaimObj, nil)
+	opResult, err := controllerutil.CreateOrPatch(ctx, r.Client, serverClaimObj, func() error {
+		// The ServerSelector and ServerRef of a ServerClaim are immutable, hence they are only set on creation.
+		if serverClaimObj.CreationTimestamp.IsZero() {
+			serverClaimObj.Spec.ServerSelector = serverSelector
+			serverClaimObj.Spec.ServerRef = serverRef
+		}
+		serverClaimObj.Spec.Power = power
+		serverClaimObj.Spec.IgnitionSecretRef = &corev1.LocalObjectReference{
+			Name: ignitionsecret.Name,
+		}
+		serverClaimObj.Spec.Image = image
+		// The cluster of a ServerClaim is kept on it for the quota usage, which has to be updated after the
+		// IroncoreMetalMachine is gone.
+		if clusterName, ok := ironcoremetalmachine.Labels[clusterapiv1beta1.ClusterNameLabel]; ok {
+			metav1.SetMetaDataLabel(&serverClaimObj.ObjectMeta, clusterapiv1beta1.ClusterNameLabel, clusterName)
+		}
+		if err := controllerutil.SetControllerReference(ironcoremetalmachine, serverClaimObj, r.Client.Scheme()); err != nil {
+			return fmt.Errorf("failed to set ControllerReference: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create or patch ServerClaim: %w", err)
 	}
@@ -318,6 +516,31 @@ func (r *IroncoreMetalMachineReconciler) applyServerClaim(ctx context.Context, l
 	return serverClaimObj, nil
 }
 
+// reconcileRAIDSupport reports the RAID layout of the IroncoreMetalMachine as unsupported, because metal-operator
+// can not configure RAID controllers.
+func reconcileRAIDSupport(ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine) {
+	raid := ironcoremetalmachine.Spec.RAID
+	if raid == nil {
+		conditions.Delete(ironcoremetalmachine, infrav1alpha1.RAIDLayoutUnsupportedCondition)
+		return
+	}
+	conditions.Set(ironcoremetalmachine, &clusterapiv1beta1.Condition{
+		Type:     infrav1alpha1.RAIDLayoutUnsupportedCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterapiv1beta1.ConditionSeverityWarning,
+		Reason:   infrav1alpha1.RAIDLayoutUnsupportedReason,
+		Message: fmt.Sprintf("the RAID layout with %d logical disks is not applied, metal-operator can not configure RAID controllers",
+			len(raid.LogicalDisks)),
+	})
+}
+
+func (r *IroncoreMetalMachineReconciler) apiReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+	return r.APIReader
+}
+
 func (r *IroncoreMetalMachineReconciler) patchIroncoreMetalMachineProviderID(ctx context.Context, log *logr.Logger, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, serverClaim *metalv1alpha1.ServerClaim) error {
 	providerID := fmt.Sprintf("metal://%s/%s", serverClaim.Namespace, serverClaim.Name)
 
@@ -345,12 +568,50 @@ func (r *IroncoreMetalMachineReconciler) ensureServerClaimBound(ctx context.Cont
 	return true, nil
 }
 
-func findAndReplaceIgnition(ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, capidatasecret *corev1.Secret) {
-	data := capidatasecret.Data["value"]
+// getBoundServer returns the Server bound to the ServerClaim of the IroncoreMetalMachine.
+// It returns nil if the ServerClaim does not exist yet or is not bound.
+func (r *IroncoreMetalMachineReconciler) getBoundServer(ctx context.Context, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine) (*metalv1alpha1.Server, error) {
+	claimObj := &metalv1alpha1.ServerClaim{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(ironcoremetalmachine), claimObj); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if claimObj.Status.Phase != metalv1alpha1.PhaseBound || claimObj.Spec.ServerRef == nil {
+		return nil, nil
+	}
 
-	// replace $${METAL_HOSTNAME} with machine name
-	hostname := "%24%24%7BMETAL_HOSTNAME%7D"
-	modifiedData := strings.ReplaceAll(string(data), hostname, ironcoremetalmachine.Name)
+	server := &metalv1alpha1.Server{}
+	if err := r.Get(ctx, client.ObjectKey{Name: claimObj.Spec.ServerRef.Name}, server); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
 
-	capidatasecret.Data["value"] = []byte(modifiedData)
+// ironcoreMetalClusterToIroncoreMetalMachines enqueues all IroncoreMetalMachines of the cluster, so that
+// changes of the cluster wide configuration are rendered into their ignitions.
+func (r *IroncoreMetalMachineReconciler) ironcoreMetalClusterToIroncoreMetalMachines(ctx context.Context, obj client.Object) []reconcile.Request {
+	clusterName, ok := obj.GetLabels()[clusterapiv1beta1.ClusterNameLabel]
+	if !ok {
+		return nil
+	}
+
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := r.List(ctx, machineList, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{clusterapiv1beta1.ClusterNameLabel: clusterName}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalMachines", "Cluster", clusterName)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(machineList.Items))
+	for i := range machineList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&machineList.Items[i])})
+	}
+	return requests
+}
+
+// serverToIroncoreMetalMachine enqueues the IroncoreMetalMachine of the ServerClaim claiming the Server, so that the
+// BIOS settings and firmware versions it reports are picked up.
+func serverToIroncoreMetalMachine(_ context.Context, obj client.Object) []reconcile.Request {
+	server, ok := obj.(*metalv1alpha1.Server)
+	if !ok || server.Spec.ServerClaimRef == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: server.Spec.ServerClaimRef.Namespace, Name: server.Spec.ServerClaimRef.Name}}}
 }
@@ -5,16 +5,28 @@ package controller
 
 import (
 	"context"
+	"time"
 
+	"github.com/go-logr/logr"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	clientgorecord "k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
 )
 
 var _ = Describe("IroncoreMetalMachine Controller", func() {
@@ -38,7 +50,9 @@ var _ = Describe("IroncoreMetalMachine Controller", func() {
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: infrav1.IroncoreMetalMachineSpec{
+						Image: "ghcr.io/ironcore-dev/os-images/gardenlinux:1443.3",
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
@@ -69,3 +83,403 @@ var _ = Describe("IroncoreMetalMachine Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("checkPinnedServer", func() {
+	var log = logr.Discard()
+
+	newClaim := func(phase metalv1alpha1.Phase) *metalv1alpha1.ServerClaim {
+		return &metalv1alpha1.ServerClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine", UID: "claim"},
+			Spec:       metalv1alpha1.ServerClaimSpec{ServerRef: &corev1.LocalObjectReference{Name: "server"}},
+			Status:     metalv1alpha1.ServerClaimStatus{Phase: phase},
+		}
+	}
+	newServer := func(claimRef *corev1.ObjectReference) *metalv1alpha1.Server {
+		return &metalv1alpha1.Server{
+			ObjectMeta: metav1.ObjectMeta{Name: "server"},
+			Spec:       metalv1alpha1.ServerSpec{ServerClaimRef: claimRef},
+		}
+	}
+	newScope := func() *scope.MachineScope {
+		return &scope.MachineScope{
+			Logger: &log,
+			IroncoreMetalMachine: &infrav1.IroncoreMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"},
+				Status:     infrav1.IroncoreMetalMachineStatus{Placement: &infrav1.PlacementStatus{Server: "server"}},
+			},
+		}
+	}
+
+	It("should keep a ServerClaim whose Server is free or claimed by it", func(ctx SpecContext) {
+		for _, server := range []*metalv1alpha1.Server{
+			newServer(nil),
+			newServer(&corev1.ObjectReference{Namespace: "default", Name: "machine", UID: "claim"}),
+		} {
+			claim := newClaim(metalv1alpha1.PhaseUnbound)
+			reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(claim, server)}
+
+			Expect(reconciler.checkPinnedServer(ctx, newScope(), claim)).To(Equal(&corev1.LocalObjectReference{Name: "server"}))
+			Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(claim), claim)).To(Succeed())
+		}
+	})
+
+	It("should recreate a ServerClaim whose Server was claimed by another ServerClaim", func(ctx SpecContext) {
+		claim := newClaim(metalv1alpha1.PhaseUnbound)
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(claim,
+			newServer(&corev1.ObjectReference{Namespace: "default", Name: "other", UID: "other"}))}
+		machineScope := newScope()
+
+		_, err := reconciler.checkPinnedServer(ctx, machineScope, claim)
+		Expect(err).To(MatchError(errWaitingForServers))
+		Expect(machineScope.IroncoreMetalMachine.Status.Placement).To(BeNil())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(claim), claim)).To(Satisfy(errors.IsNotFound))
+	})
+
+	It("should keep a bound ServerClaim", func(ctx SpecContext) {
+		claim := newClaim(metalv1alpha1.PhaseBound)
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(claim)}
+
+		Expect(reconciler.checkPinnedServer(ctx, newScope(), claim)).To(Equal(&corev1.LocalObjectReference{Name: "server"}))
+	})
+})
+
+var _ = Describe("placeServerClaim", func() {
+	var log = logr.Discard()
+
+	It("should pin the ServerClaims of clusters with quota classes so that bursts do not exceed them", func(ctx SpecContext) {
+		newServer := func(name string) *metalv1alpha1.Server {
+			return &metalv1alpha1.Server{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"gpu": "true"}},
+				Status:     metalv1alpha1.ServerStatus{State: metalv1alpha1.ServerStateAvailable},
+			}
+		}
+		newMachine := func(name string) *infrav1.IroncoreMetalMachine {
+			return &infrav1.IroncoreMetalMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+			}
+		}
+		ironcoremetalcluster := &infrav1.IroncoreMetalCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+			Spec: infrav1.IroncoreMetalClusterSpec{Quota: &infrav1.ServerQuota{Classes: []infrav1.ServerQuotaClass{{
+				Name:           "gpu",
+				ServerSelector: metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+				MaxServers:     1,
+			}}}},
+		}
+		machines := []*infrav1.IroncoreMetalMachine{newMachine("machine-0"), newMachine("machine-1")}
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(
+			ironcoremetalcluster, machines[0], machines[1], newServer("gpu-0"), newServer("gpu-1"),
+		)}
+		newScope := func(machine *infrav1.IroncoreMetalMachine) *scope.MachineScope {
+			return &scope.MachineScope{
+				Logger:               &log,
+				Cluster:              &clusterapiv1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}},
+				Machine:              &clusterapiv1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: machine.Name}},
+				IroncoreMetalCluster: ironcoremetalcluster,
+				IroncoreMetalMachine: machine,
+			}
+		}
+
+		// Both machines are placed before metal-operator binds the ServerClaim of the first one.
+		serverRef, err := reconciler.placeServerClaim(ctx, newScope(machines[0]), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serverRef).NotTo(BeNil())
+		Expect(reconciler.Create(ctx, &metalv1alpha1.ServerClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine-0", Labels: map[string]string{clusterapiv1beta1.ClusterNameLabel: "cluster"}},
+			Spec:       metalv1alpha1.ServerClaimSpec{ServerRef: serverRef},
+		})).To(Succeed())
+
+		_, err = reconciler.placeServerClaim(ctx, newScope(machines[1]), nil)
+		Expect(err).To(MatchError(errQuotaExceeded))
+	})
+})
+
+var _ = Describe("checkMachineDeploymentFits", func() {
+	It("should only emit a warning event when the number of matching Servers changes", func(ctx SpecContext) {
+		recorder := clientgorecord.NewFakeRecorder(10)
+		record.InitFromRecorder(recorder)
+
+		deployment := &clusterapiv1beta1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "workers"},
+			Spec:       clusterapiv1beta1.MachineDeploymentSpec{Replicas: ptr.To[int32](3)},
+		}
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(deployment)}
+		machineScope := &scope.MachineScope{Machine: &clusterapiv1beta1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Labels:    map[string]string{clusterapiv1beta1.MachineDeploymentNameLabel: "workers"},
+		}}}
+
+		for _, matching := range []int{2, 2, 1, 3, 2} {
+			Expect(reconciler.checkMachineDeploymentFits(ctx, machineScope, placement.Availability{Matching: matching})).To(Succeed())
+		}
+		Expect(recorder.Events).To(HaveLen(3))
+		Expect(<-recorder.Events).To(ContainSubstring("only 2 Servers match"))
+		Expect(<-recorder.Events).To(ContainSubstring("only 1 Servers match"))
+		Expect(<-recorder.Events).To(ContainSubstring("only 2 Servers match"))
+	})
+})
+
+var _ = Describe("reconcileMachineClassHash", func() {
+	var (
+		class        *infrav1.IroncoreMetalMachineClass
+		machineScope *scope.MachineScope
+	)
+
+	BeforeEach(func() {
+		class = &infrav1.IroncoreMetalMachineClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "large"},
+			Spec:       infrav1.IroncoreMetalMachineClassSpec{Image: "registry.example.com/os:1"},
+		}
+		machineScope = &scope.MachineScope{
+			IroncoreMetalMachine: &infrav1.IroncoreMetalMachine{Spec: infrav1.IroncoreMetalMachineSpec{Class: "large"}},
+			IroncoreMetalCluster: &infrav1.IroncoreMetalCluster{},
+		}
+	})
+
+	// reconcileClass sets the class and records the effective settings like reconcileNormal does.
+	reconcileClass := func() {
+		machineScope.SetMachineClass(class)
+		Expect(reconcileMachineClassHash(machineScope)).To(Succeed())
+		machineScope.IroncoreMetalMachine.Status.EffectiveSettings = machineScope.Settings.DeepCopy()
+	}
+
+	It("should follow the class until the ServerClaim exists", func() {
+		reconcileClass()
+		hash := machineScope.IroncoreMetalMachine.Status.MachineClassHash
+		Expect(hash).NotTo(BeEmpty())
+
+		class.Spec.Image = "registry.example.com/os:2"
+		reconcileClass()
+		Expect(machineScope.IroncoreMetalMachine.Status.MachineClassHash).NotTo(Equal(hash))
+		Expect(machineScope.Settings.Image).To(Equal("registry.example.com/os:2"))
+		Expect(conditions.IsTrue(machineScope.IroncoreMetalMachine, infrav1.MachineClassUpToDateCondition)).To(BeTrue())
+	})
+
+	It("should keep the hash and the settings once the ServerClaim exists", func() {
+		reconcileClass()
+		hash := machineScope.IroncoreMetalMachine.Status.MachineClassHash
+		conditions.MarkTrue(machineScope.IroncoreMetalMachine, infrav1.ServerAvailableCondition)
+
+		class.Spec.Image = "registry.example.com/os:2"
+		reconcileClass()
+		Expect(machineScope.IroncoreMetalMachine.Status.MachineClassHash).To(Equal(hash))
+		Expect(machineScope.Settings.Image).To(Equal("registry.example.com/os:1"))
+		Expect(conditions.GetReason(machineScope.IroncoreMetalMachine, infrav1.MachineClassUpToDateCondition)).To(Equal(infrav1.MachineClassChangedReason))
+	})
+
+	It("should remove the hash and the condition of machines without class", func() {
+		reconcileClass()
+		class = nil
+		reconcileClass()
+		Expect(machineScope.IroncoreMetalMachine.Status.MachineClassHash).To(BeEmpty())
+		Expect(conditions.Has(machineScope.IroncoreMetalMachine, infrav1.MachineClassUpToDateCondition)).To(BeFalse())
+	})
+})
+
+var _ = Describe("reconcileImagePullSecretSupport", func() {
+	It("should report image pull Secrets as unsupported by the boot flow while they are used", func() {
+		machine := &infrav1.IroncoreMetalMachine{}
+		reconcileImagePullSecretSupport(machine, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pull"}})
+		Expect(conditions.IsTrue(machine, infrav1.ImagePullSecretUnsupportedCondition)).To(BeTrue())
+		Expect(conditions.GetReason(machine, infrav1.ImagePullSecretUnsupportedCondition)).To(Equal(infrav1.ImagePullSecretUnsupportedReason))
+
+		reconcileImagePullSecretSupport(machine, nil)
+		Expect(conditions.Has(machine, infrav1.ImagePullSecretUnsupportedCondition)).To(BeFalse())
+	})
+})
+
+var _ = Describe("reconcileRAIDSupport", func() {
+	It("should report RAID layouts as unsupported while they are set", func() {
+		machine := &infrav1.IroncoreMetalMachine{Spec: infrav1.IroncoreMetalMachineSpec{RAID: &infrav1.RAIDSpec{
+			LogicalDisks: []infrav1.LogicalDisk{{Name: "root", Level: "1", NumberOfPhysicalDisks: 2}},
+		}}}
+		reconcileRAIDSupport(machine)
+		Expect(conditions.IsTrue(machine, infrav1.RAIDLayoutUnsupportedCondition)).To(BeTrue())
+		Expect(conditions.GetReason(machine, infrav1.RAIDLayoutUnsupportedCondition)).To(Equal(infrav1.RAIDLayoutUnsupportedReason))
+
+		machine.Spec.RAID = nil
+		reconcileRAIDSupport(machine)
+		Expect(conditions.Has(machine, infrav1.RAIDLayoutUnsupportedCondition)).To(BeFalse())
+	})
+})
+
+var _ = Describe("reconcileBIOSSettings", func() {
+	var (
+		log          = logr.Discard()
+		server       *metalv1alpha1.Server
+		machineScope *scope.MachineScope
+	)
+
+	BeforeEach(func() {
+		server = &metalv1alpha1.Server{
+			ObjectMeta: metav1.ObjectMeta{Name: "server"},
+			Spec: metalv1alpha1.ServerSpec{BIOS: []metalv1alpha1.BIOSSettings{
+				{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Disabled"}},
+			}},
+			Status: metalv1alpha1.ServerStatus{PowerState: metalv1alpha1.ServerOffPowerState},
+		}
+		machineScope = &scope.MachineScope{
+			Logger:               &log,
+			IroncoreMetalMachine: &infrav1.IroncoreMetalMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"}},
+			Settings: infrav1.MachineSettings{BIOSSettings: &infrav1.BIOSSettings{
+				Version:  "2.1",
+				Settings: map[string]string{"SriovGlobalEnable": "Enabled", "ProcVirtualization": "Enabled"},
+			}},
+		}
+	})
+
+	It("should write the settings, restart the running Server once and revert them", func(ctx SpecContext) {
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(server)}
+
+		written, applied, err := reconciler.reconcileBIOSSettings(ctx, machineScope, server)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(written).To(BeTrue())
+		Expect(applied).To(BeFalse())
+		Expect(machineScope.IroncoreMetalMachine.Status.BIOS.WrittenAt).NotTo(BeNil())
+		Expect(machineScope.IroncoreMetalMachine.Status.BIOS).To(Equal(&infrav1.BIOSStatus{
+			Server:    "server",
+			Version:   "2.1",
+			Settings:  map[string]string{"SriovGlobalEnable": "Enabled", "ProcVirtualization": "Enabled"},
+			Previous:  map[string]string{"SriovGlobalEnable": "Disabled"},
+			WrittenAt: machineScope.IroncoreMetalMachine.Status.BIOS.WrittenAt,
+		}))
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(server), server)).To(Succeed())
+		Expect(server.Spec.BIOS[0].Settings).To(HaveKeyWithValue("ProcVirtualization", "Enabled"))
+		Expect(server.Annotations).NotTo(HaveKey(metalv1alpha1.OperationAnnotation))
+
+		// metal-operator staged the settings while the Server was powered on.
+		server.Status.PowerState = metalv1alpha1.ServerOnPowerState
+		server.Status.Conditions = []metav1.Condition{{Type: "Reboot needed"}}
+		_, applied, err = reconciler.reconcileBIOSSettings(ctx, machineScope, server)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(BeFalse())
+		Expect(machineScope.IroncoreMetalMachine.Status.BIOS.Restarted).To(BeTrue())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(server), server)).To(Succeed())
+		Expect(server.Annotations).To(HaveKeyWithValue(metalv1alpha1.OperationAnnotation, "ForceRestart"))
+
+		// metal-operator removes the annotation once it restarted the Server, which keeps its condition.
+		delete(server.Annotations, metalv1alpha1.OperationAnnotation)
+		Expect(reconciler.Update(ctx, server)).To(Succeed())
+		server.Status.PowerState = metalv1alpha1.ServerOnPowerState
+		server.Status.Conditions = []metav1.Condition{{Type: "Reboot needed"}}
+		_, _, err = reconciler.reconcileBIOSSettings(ctx, machineScope, server)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(server), server)).To(Succeed())
+		Expect(server.Annotations).NotTo(HaveKey(metalv1alpha1.OperationAnnotation))
+
+		server.Status.BIOS = metalv1alpha1.BIOSSettings{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Enabled", "ProcVirtualization": "Enabled"}}
+		_, applied, err = reconciler.reconcileBIOSSettings(ctx, machineScope, server)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(BeTrue())
+
+		Expect(reconciler.revertBIOSSettings(ctx, machineScope)).To(Succeed())
+		Expect(machineScope.IroncoreMetalMachine.Status.BIOS).To(BeNil())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(server), server)).To(Succeed())
+		Expect(server.Spec.BIOS).To(Equal([]metalv1alpha1.BIOSSettings{
+			{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Disabled"}},
+		}))
+	})
+
+	It("should fail settings the Server keeps reporting with another value", func(ctx SpecContext) {
+		// metal-operator only applies the settings the Server does not report yet.
+		server.Status.BIOS = metalv1alpha1.BIOSSettings{Version: "2.1", Settings: map[string]string{"SriovGlobalEnable": "Disabled", "ProcVirtualization": "Enabled"}}
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(server)}
+
+		_, applied, err := reconciler.reconcileBIOSSettings(ctx, machineScope, server)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(BeFalse())
+
+		machineScope.IroncoreMetalMachine.Status.BIOS.WrittenAt = ptr.To(metav1.NewTime(time.Now().Add(-biosSettingsTimeout - time.Minute)))
+		_, applied, err = reconciler.reconcileBIOSSettings(ctx, machineScope, server)
+		Expect(err).To(MatchError(errBIOSSettingsNotApplied))
+		Expect(err.Error()).To(ContainSubstring("BIOS settings SriovGlobalEnable for version 2.1"))
+		Expect(applied).To(BeFalse())
+		Expect(biosFailureReason(err)).To(Equal(infrav1.BIOSSettingsNotAppliedReason))
+	})
+
+	It("should refuse Servers reporting another BIOS version", func(ctx SpecContext) {
+		server.Status.BIOS.Version = "2.0"
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(server)}
+
+		_, _, err := reconciler.reconcileBIOSSettings(ctx, machineScope, server)
+		Expect(err).To(MatchError(errBIOSVersionMismatch))
+		Expect(machineScope.IroncoreMetalMachine.Status.BIOS).To(BeNil())
+	})
+})
+
+// newMetalServer returns a bound Server the way metal-operator reports it, with the BIOS settings for the version it
+// runs applied.
+func newMetalServer() *metalv1alpha1.Server {
+	return &metalv1alpha1.Server{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "compute-r1-01-system-0",
+		},
+		Spec: metalv1alpha1.ServerSpec{
+			UUID:           "38947555-7742-3448-3784-823347823834",
+			Power:          metalv1alpha1.PowerOn,
+			BMCRef:         &corev1.LocalObjectReference{Name: "compute-r1-01"},
+			BIOS:           []metalv1alpha1.BIOSSettings{{Version: "U46 v2.72", Settings: map[string]string{"BootMode": "Uefi"}}},
+			ServerClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "machine"},
+		},
+		Status: metalv1alpha1.ServerStatus{
+			Manufacturer: "HPE",
+			Model:        "ProLiant DL380 Gen10 Plus",
+			SerialNumber: "CZ2D1234AB",
+			PowerState:   metalv1alpha1.ServerOnPowerState,
+			State:        metalv1alpha1.ServerStateReserved,
+			NetworkInterfaces: []metalv1alpha1.NetworkInterface{
+				{Name: "eth0", IP: metalv1alpha1.MustParseIP("10.0.1.21"), MACAddress: "b4:7a:f1:2c:3d:4e"},
+			},
+			BIOS: metalv1alpha1.BIOSSettings{Version: "U46 v2.72", Settings: map[string]string{"BootMode": "Uefi"}},
+		},
+	}
+}
+
+var _ = Describe("reconcileFirmwareCompliance", func() {
+	var (
+		log          = logr.Discard()
+		server       *metalv1alpha1.Server
+		bmc          *metalv1alpha1.BMC
+		machineScope *scope.MachineScope
+	)
+
+	BeforeEach(func() {
+		server = newMetalServer()
+		bmc = &metalv1alpha1.BMC{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute-r1-01"},
+			Status:     metalv1alpha1.BMCStatus{Manufacturer: "HPE", Model: "iLO 5", FirmwareVersion: "2.78"},
+		}
+		machineScope = &scope.MachineScope{
+			Logger:               &log,
+			IroncoreMetalMachine: &infrav1.IroncoreMetalMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"}},
+			Settings: infrav1.MachineSettings{FirmwarePolicy: &infrav1.FirmwarePolicy{
+				MinBIOSVersion: "U46 v2.70",
+				MinBMCVersion:  "2.78",
+			}},
+		}
+	})
+
+	It("should not report a compliant Server", func(ctx SpecContext) {
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(server, bmc)}
+		Expect(reconciler.reconcileFirmwareCompliance(ctx, machineScope, server)).To(Succeed())
+		Expect(conditions.Get(machineScope.IroncoreMetalMachine, infrav1.FirmwareNonCompliantCondition)).To(BeNil())
+	})
+
+	It("should report a Server running older firmware", func(ctx SpecContext) {
+		bmc.Status.FirmwareVersion = "2.72"
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(server, bmc)}
+		Expect(reconciler.reconcileFirmwareCompliance(ctx, machineScope, server)).To(Succeed())
+		Expect(conditions.IsTrue(machineScope.IroncoreMetalMachine, infrav1.FirmwareNonCompliantCondition)).To(BeTrue())
+		Expect(conditions.GetReason(machineScope.IroncoreMetalMachine, infrav1.FirmwareNonCompliantCondition)).To(Equal(infrav1.FirmwareOutdatedReason))
+	})
+
+	It("should report unknown versions as Unknown", func(ctx SpecContext) {
+		// metal-operator does not report the BIOS version without BIOS settings for it.
+		server.Spec.BIOS = nil
+		server.Status.BIOS = metalv1alpha1.BIOSSettings{}
+		reconciler := &IroncoreMetalMachineReconciler{Client: newIndexedFakeClient(server)}
+		Expect(reconciler.reconcileFirmwareCompliance(ctx, machineScope, server)).To(Succeed())
+		Expect(conditions.IsUnknown(machineScope.IroncoreMetalMachine, infrav1.FirmwareNonCompliantCondition)).To(BeTrue())
+		Expect(conditions.GetReason(machineScope.IroncoreMetalMachine, infrav1.FirmwareNonCompliantCondition)).To(Equal(infrav1.FirmwareVersionUnknownReason))
+	})
+})
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	corev1 "k8s.io/api/core/v1"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+// requiresBMCFirmware reports whether the firmware policy requires a BMC version, which needs the BMCs to be listed.
+func requiresBMCFirmware(policy *infrav1alpha1.FirmwarePolicy) bool {
+	return policy != nil && policy.MinBMCVersion != ""
+}
+
+// bmcFirmware returns the firmware versions of the BMCs by their names, see placement.BMCFirmware.
+func bmcFirmware(ctx context.Context, c client.Client) (map[string]string, error) {
+	bmcList := &metalv1alpha1.BMCList{}
+	if err := c.List(ctx, bmcList); err != nil {
+		return nil, fmt.Errorf("failed to list BMCs: %w", err)
+	}
+	return placement.BMCFirmware(bmcList.Items), nil
+}
+
+// reconcileFirmwareCompliance reports whether the bound Server of the IroncoreMetalMachine complies with its firmware
+// policy. Unbound machines only claim Servers which do not run older firmware, hence the condition is only set once a
+// Server is bound. Versions metal-operator does not report set the condition to Unknown.
+func (r *IroncoreMetalMachineReconciler) reconcileFirmwareCompliance(ctx context.Context, machineScope *scope.MachineScope, server *metalv1alpha1.Server) error {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	policy := machineScope.Settings.FirmwarePolicy
+	if server == nil || policy == nil {
+		conditions.Delete(ironcoremetalmachine, infrav1alpha1.FirmwareNonCompliantCondition)
+		return nil
+	}
+	var bmcFirmware map[string]string
+	if requiresBMCFirmware(policy) && server.Spec.BMCRef != nil {
+		bmc := &metalv1alpha1.BMC{}
+		if err := r.Get(ctx, client.ObjectKey{Name: server.Spec.BMCRef.Name}, bmc); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to get BMC %s: %w", server.Spec.BMCRef.Name, err)
+		} else if err == nil {
+			bmcFirmware = placement.BMCFirmware([]metalv1alpha1.BMC{*bmc})
+		}
+	}
+
+	violations, unknown := placement.FirmwareViolations(placement.FirmwareOf(server, bmcFirmware), policy)
+	switch {
+	case len(violations) > 0:
+		conditions.Set(ironcoremetalmachine, &clusterapiv1beta1.Condition{
+			Type:     infrav1alpha1.FirmwareNonCompliantCondition,
+			Status:   corev1.ConditionTrue,
+			Severity: clusterapiv1beta1.ConditionSeverityWarning,
+			Reason:   infrav1alpha1.FirmwareOutdatedReason,
+			Message:  fmt.Sprintf("Server %s does not comply with the firmware policy: %s", server.Name, strings.Join(violations, ", ")),
+		})
+	case len(unknown) > 0:
+		conditions.Set(ironcoremetalmachine, &clusterapiv1beta1.Condition{
+			Type:     infrav1alpha1.FirmwareNonCompliantCondition,
+			Status:   corev1.ConditionUnknown,
+			Severity: clusterapiv1beta1.ConditionSeverityInfo,
+			Reason:   infrav1alpha1.FirmwareVersionUnknownReason,
+			Message:  fmt.Sprintf("Server %s does not report its firmware: %s", server.Name, strings.Join(unknown, ", ")),
+		})
+	default:
+		conditions.Delete(ironcoremetalmachine, infrav1alpha1.FirmwareNonCompliantCondition)
+	}
+	return nil
+}
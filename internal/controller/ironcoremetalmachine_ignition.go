@@ -0,0 +1,436 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/bootstrap"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/ignition"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/metadata"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+const (
+	// AccessSSHAuthorizedKeysKey is the key of the authorized SSH keys in the Secret of an access user.
+	AccessSSHAuthorizedKeysKey = "sshAuthorizedKeys"
+	// AccessPasswordHashKey is the key of the password hash in the Secret of an access user.
+	AccessPasswordHashKey = "passwordHash"
+
+	// ignitionCompressionThreshold is the size from which on files embedded into the ignition are compressed.
+	ignitionCompressionThreshold = 4 * 1024
+	// ignitionSizeWarningThreshold is the ignition size from which on the IgnitionReady condition warns.
+	ignitionSizeWarningThreshold = corev1.MaxSecretSize * 3 / 4
+	// ignitionSizeLimit is the maximum size of an ignition stored in a single Secret.
+	ignitionSizeLimit = corev1.MaxSecretSize * 9 / 10
+)
+
+var (
+	// errInvalidBootstrapData is returned if the bootstrap data can not be rendered into a valid ignition.
+	errInvalidBootstrapData = errors.New("invalid bootstrap data")
+	// errUnsupportedBootstrapFormat is returned if the bootstrap data is in a format which is not supported.
+	errUnsupportedBootstrapFormat = errors.New("unsupported bootstrap format")
+	// errInvalidTrustedCABundle is returned if the trusted CA bundle of the IroncoreMetalCluster contains no certificate.
+	errInvalidTrustedCABundle = errors.New("invalid trusted CA bundle")
+)
+
+func (r *IroncoreMetalMachineReconciler) applyMetadataSecret(ctx context.Context, log *logr.Logger, machineScope *scope.MachineScope, server *metalv1alpha1.Server) (*corev1.Secret, error) {
+	doc := metadata.New(machineScope.Cluster, machineScope.Machine, machineScope.IroncoreMetalMachine, server)
+	data, err := doc.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	secretObj := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("metadata-%s", machineScope.IroncoreMetalMachine.Name),
+			Namespace: machineScope.IroncoreMetalMachine.Namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+	}
+
+	opResult, err := controllerutil.CreateOrPatch(ctx, r.Client, secretObj, func() error {
+		secretObj.Data = map[string][]byte{
+			DefaultMetadataSecretKeyName: data,
+		}
+		if err := controllerutil.SetControllerReference(machineScope.IroncoreMetalMachine, secretObj, r.Client.Scheme()); err != nil {
+			return fmt.Errorf("failed to set ControllerReference: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or patch the MetadataSecret: %w", err)
+	}
+	log.Info("Created or Patched MetadataSecret", "MetadataSecret", secretObj.Name, "Operation", opResult)
+
+	return secretObj, nil
+}
+
+func (r *IroncoreMetalMachineReconciler) applyIgnitionSecret(ctx context.Context, log *logr.Logger, machineScope *scope.MachineScope, capidatasecret *corev1.Secret, metadataSecret *corev1.Secret) (*corev1.Secret, error) {
+	secretObj := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("ignition-%s", capidatasecret.Name),
+			Namespace: capidatasecret.Namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+	}
+
+	data, err := r.renderBootstrapData(ctx, log, machineScope, capidatasecret, secretObj.Name, metadataSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	opResult, err := controllerutil.CreateOrPatch(ctx, r.Client, secretObj, func() error {
+		secretObj.Data = data
+		if err := controllerutil.SetControllerReference(capidatasecret, secretObj, r.Client.Scheme()); err != nil {
+			return fmt.Errorf("failed to set ControllerReference: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or patch the IgnitionSecret: %w", err)
+	}
+	log.Info("Created or Patched IgnitionSecret", "IgnitionSecret", secretObj.Name, "Operation", opResult)
+
+	return secretObj, nil
+}
+
+// renderBootstrapData returns the data of the ignition Secret. Ignitions and cloud-configs converted into an
+// ignition are amended and stored under the ignition key. Other formats are passed through unchanged under the
+// ignition key as well, with their format under the format key.
+func (r *IroncoreMetalMachineReconciler) renderBootstrapData(ctx context.Context, log *logr.Logger, machineScope *scope.MachineScope, capidatasecret *corev1.Secret, ignitionSecretName string, metadataSecret *corev1.Secret) (map[string][]byte, error) {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	format, err := bootstrap.DetectFormat(capidatasecret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errUnsupportedBootstrapFormat, err)
+	}
+	value := bootstrap.Substitute(capidatasecret.Data[bootstrap.ValueKey], bootstrapVariables(ironcoremetalmachine))
+
+	var config ignition.Config
+	switch {
+	case format == bootstrap.FormatIgnition:
+		config, err = ignition.Parse(value)
+	case format == bootstrap.FormatCloudConfig && machineScope.Settings.BootstrapDataMode != infrav1alpha1.BootstrapDataModePassthrough:
+		config, err = ignition.FromCloudConfig(ignition.RenderJinja(value, ignition.InstanceData{Hostname: ironcoremetalmachine.Name}))
+	default:
+		// metal-operator only hands the ignition key to the boot configuration of the server.
+		log.Info("Passing bootstrap data through", "Format", format)
+		return map[string][]byte{
+			DefaultIgnitionSecretKeyName: value,
+			bootstrap.FormatKey:          []byte(format),
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidBootstrapData, err)
+	}
+
+	if err := r.addAccessUsers(ctx, config, machineScope.IroncoreMetalCluster); err != nil {
+		return nil, err
+	}
+	if err := addNodeConfig(config, machineScope.Cluster, machineScope.IroncoreMetalCluster); err != nil {
+		return nil, err
+	}
+	if embedsMetadata(machineScope.Settings) {
+		config.AddFile(ignition.File{
+			Path:     machineScope.Settings.Metadata.IgnitionPath,
+			Contents: metadataSecret.Data[DefaultMetadataSecretKeyName],
+		})
+	}
+	// Catch malformed ignitions before the server boots with them.
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidBootstrapData, err)
+	}
+	if err := config.CompressFiles(ignitionCompressionThreshold); err != nil {
+		return nil, fmt.Errorf("failed to compress ignition files: %w", err)
+	}
+	if err := r.splitIgnition(ctx, log, ironcoremetalmachine, capidatasecret, ignitionSecretName, config); err != nil {
+		return nil, err
+	}
+
+	ignitionData, err := config.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ignition config: %w", err)
+	}
+	return map[string][]byte{
+		DefaultIgnitionSecretKeyName: ignitionData,
+	}, nil
+}
+
+// addAccessUsers merges the users of the access spec of the IroncoreMetalCluster into the ignition config.
+// The break-glass user is only added until it expires.
+func (r *IroncoreMetalMachineReconciler) addAccessUsers(ctx context.Context, config ignition.Config, ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster) error {
+	access := ironcoremetalcluster.Spec.Access
+	if access == nil {
+		return nil
+	}
+	for _, user := range access.Users {
+		if err := r.addAccessUser(ctx, config, ironcoremetalcluster.Namespace, user); err != nil {
+			return err
+		}
+	}
+	if breakGlass := access.BreakGlass; breakGlass != nil && time.Now().Before(breakGlass.ExpiresAt.Time) {
+		if err := r.addAccessUser(ctx, config, ironcoremetalcluster.Namespace, breakGlass.AccessUser); err != nil {
+			return err
+		}
+		config.AddUserExpiry(breakGlass.Name, breakGlass.ExpiresAt.Time)
+	}
+	return nil
+}
+
+func (r *IroncoreMetalMachineReconciler) addAccessUser(ctx context.Context, config ignition.Config, namespace string, user infrav1alpha1.AccessUser) error {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: user.SecretRef.Name}, secret); err != nil {
+		return fmt.Errorf("failed to get Secret of access user %s: %w", user.Name, err)
+	}
+
+	var keys []string
+	for _, line := range strings.Split(string(secret.Data[AccessSSHAuthorizedKeysKey]), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+	config.AddUser(ignition.User{
+		Name:              user.Name,
+		PasswordHash:      string(secret.Data[AccessPasswordHashKey]),
+		Groups:            user.Groups,
+		SSHAuthorizedKeys: keys,
+	})
+	return nil
+}
+
+// addNodeConfig renders the registry mirrors, the trusted CA bundle and the proxy settings of the
+// IroncoreMetalCluster into the ignition config.
+func addNodeConfig(config ignition.Config, cluster *clusterapiv1beta1.Cluster, ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster) error {
+	spec := ironcoremetalcluster.Spec
+	for _, mirror := range spec.RegistryMirrors {
+		config.AddRegistryMirror(mirror.Registry, mirror.Endpoints)
+	}
+	if spec.TrustedCABundle != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(spec.TrustedCABundle)) {
+			return fmt.Errorf("%w: IroncoreMetalCluster %s contains no PEM encoded certificate", errInvalidTrustedCABundle, ironcoremetalcluster.Name)
+		}
+		config.AddTrustedCA([]byte(spec.TrustedCABundle))
+	}
+	if spec.Proxy != nil {
+		config.AddProxy(ignition.Proxy{
+			HTTPProxy:  spec.Proxy.HTTPProxy,
+			HTTPSProxy: spec.Proxy.HTTPSProxy,
+			NoProxy:    noProxy(cluster, ironcoremetalcluster),
+		})
+	}
+	return nil
+}
+
+// noProxy returns the hosts which are reached without proxy: localhost, the control plane endpoint, the pod and
+// service networks and service domain of the cluster, and the ones configured in the IroncoreMetalCluster.
+func noProxy(cluster *clusterapiv1beta1.Cluster, ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1", ".svc"}
+	if endpoint := ironcoremetalcluster.Spec.ControlPlaneEndpoint; endpoint.Host != "" {
+		hosts = append(hosts, endpoint.Host)
+	}
+	if network := cluster.Spec.ClusterNetwork; network != nil {
+		if network.Pods != nil {
+			hosts = append(hosts, network.Pods.CIDRBlocks...)
+		}
+		if network.Services != nil {
+			hosts = append(hosts, network.Services.CIDRBlocks...)
+		}
+		if network.ServiceDomain != "" {
+			hosts = append(hosts, "."+network.ServiceDomain)
+		}
+	}
+	hosts = append(hosts, ironcoremetalcluster.Spec.Proxy.NoProxy...)
+
+	seen := sets.New[string]()
+	unique := hosts[:0]
+	for _, host := range hosts {
+		if !seen.Has(host) {
+			seen.Insert(host)
+			unique = append(unique, host)
+		}
+	}
+	return unique
+}
+
+// splitIgnition checks the size of the ignition config and moves files into part Secrets if it is too large
+// for a single Secret. The parts are merged into the config again through the ignition part server.
+func (r *IroncoreMetalMachineReconciler) splitIgnition(ctx context.Context, log *logr.Logger, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, capidatasecret *corev1.Secret, ignitionSecretName string, config ignition.Config) error {
+	data, err := config.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal ignition config: %w", err)
+	}
+	if size := len(data); size > ignitionSizeWarningThreshold {
+		conditions.MarkFalse(ironcoremetalmachine, infrav1alpha1.IgnitionReadyCondition, infrav1alpha1.IgnitionSizeNearLimitReason, clusterapiv1beta1.ConditionSeverityWarning,
+			"Ignition is %d bytes large, Secrets are limited to %d bytes", size, corev1.MaxSecretSize)
+	} else {
+		conditions.MarkTrue(ironcoremetalmachine, infrav1alpha1.IgnitionReadyCondition)
+	}
+
+	parts, err := config.Split(ignitionSizeLimit)
+	if err == nil && len(parts) > 0 && r.IgnitionPartsURL == "" {
+		err = errors.New("no ignition parts URL is configured")
+	}
+	if err != nil {
+		conditions.MarkFalse(ironcoremetalmachine, infrav1alpha1.IgnitionReadyCondition, infrav1alpha1.IgnitionTooLargeReason, clusterapiv1beta1.ConditionSeverityError,
+			"Ignition of %d bytes can not be split into Secrets: %s", len(data), err.Error())
+		return fmt.Errorf("failed to split ignition config: %w", err)
+	}
+
+	partNames := sets.New[string]()
+	for i, part := range parts {
+		partData, err := part.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to marshal ignition part: %w", err)
+		}
+
+		partObj := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-part-%d", ignitionSecretName, i),
+				Namespace: capidatasecret.Namespace,
+			},
+		}
+		opResult, err := controllerutil.CreateOrPatch(ctx, r.Client, partObj, func() error {
+			if partObj.Labels == nil {
+				partObj.Labels = map[string]string{}
+			}
+			partObj.Labels[ignition.PartLabel] = ignitionSecretName
+			partObj.Data = map[string][]byte{
+				ignition.PartSecretKeyName: partData,
+			}
+			if err := controllerutil.SetControllerReference(capidatasecret, partObj, r.Client.Scheme()); err != nil {
+				return fmt.Errorf("failed to set ControllerReference: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create or patch ignition part Secret: %w", err)
+		}
+		log.Info("Created or Patched ignition part Secret", "Secret", partObj.Name, "Operation", opResult)
+
+		partNames.Insert(partObj.Name)
+		config.AddMergeSource(strings.TrimSuffix(r.IgnitionPartsURL, "/")+ignition.PartPath(partObj.Namespace, partObj.Name, partData), partData)
+	}
+	if len(parts) > 0 && len(r.IgnitionPartsCA) > 0 {
+		config.AddCertificateAuthority(r.IgnitionPartsCA)
+	}
+
+	// Remove parts which are not needed anymore.
+	partList := &corev1.SecretList{}
+	if err := r.List(ctx, partList, client.InNamespace(capidatasecret.Namespace), client.MatchingLabels{ignition.PartLabel: ignitionSecretName}); err != nil {
+		return fmt.Errorf("failed to list ignition part Secrets: %w", err)
+	}
+	for i := range partList.Items {
+		if partNames.Has(partList.Items[i].Name) {
+			continue
+		}
+		if err := r.Delete(ctx, &partList.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete ignition part Secret: %w", err)
+		}
+		log.Info("Deleted stale ignition part Secret", "Secret", partList.Items[i].Name)
+	}
+	return nil
+}
+
+// embedsMetadata reports whether the metadata document is written to the ignition of the IroncoreMetalMachine.
+func embedsMetadata(settings infrav1alpha1.MachineSettings) bool {
+	return settings.Metadata != nil && settings.Metadata.IgnitionPath != ""
+}
+
+// bootstrapVariables returns the variables which are substituted in the bootstrap data of the IroncoreMetalMachine.
+func bootstrapVariables(ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine) map[string]string {
+	return map[string]string{
+		bootstrap.HostnameVariable: ironcoremetalmachine.Name,
+	}
+}
+
+// breakGlassRequeueAfter returns the duration until the break-glass user of the IroncoreMetalCluster expires,
+// or zero if there is none.
+func breakGlassRequeueAfter(ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster) time.Duration {
+	if ironcoremetalcluster.Spec.Access == nil || ironcoremetalcluster.Spec.Access.BreakGlass == nil {
+		return 0
+	}
+	if until := time.Until(ironcoremetalcluster.Spec.Access.BreakGlass.ExpiresAt.Time); until > 0 {
+		return until
+	}
+	return 0
+}
+
+// secretToIroncoreMetalMachines enqueues the IroncoreMetalMachines of all clusters which reference the Secret
+// for access users or for pulling images, and the IroncoreMetalMachines referencing it as image pull Secret,
+// so that rotated credentials are picked up.
+func (r *IroncoreMetalMachineReconciler) secretToIroncoreMetalMachines(ctx context.Context, obj client.Object) []reconcile.Request {
+	clusterList := &infrav1alpha1.IroncoreMetalClusterList{}
+	if err := r.List(ctx, clusterList, client.InNamespace(obj.GetNamespace()), client.MatchingFields{ironcoreMetalClusterSecretField: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalClusters")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range clusterList.Items {
+		requests = append(requests, r.ironcoreMetalClusterToIroncoreMetalMachines(ctx, &clusterList.Items[i])...)
+	}
+
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := r.List(ctx, machineList, client.InNamespace(obj.GetNamespace()), client.MatchingFields{ironcoreMetalMachineImagePullSecretField: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalMachines")
+		return requests
+	}
+	for i := range machineList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&machineList.Items[i])})
+	}
+	return requests
+}
+
+// accessSecretNames returns the names of the Secrets referenced by the access spec of the IroncoreMetalCluster.
+func accessSecretNames(ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster) sets.Set[string] {
+	names := sets.New[string]()
+	access := ironcoremetalcluster.Spec.Access
+	if access == nil {
+		return names
+	}
+	for _, user := range access.Users {
+		names.Insert(user.SecretRef.Name)
+	}
+	if access.BreakGlass != nil {
+		names.Insert(access.BreakGlass.SecretRef.Name)
+	}
+	return names
+}
+
+// bootstrapFailureReason returns the terminal failure reason of an error rendering the bootstrap data,
+// or an empty string if the error is transient.
+func bootstrapFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errUnsupportedBootstrapFormat):
+		return infrav1alpha1.UnsupportedBootstrapFormatReason
+	case errors.Is(err, errInvalidBootstrapData):
+		return infrav1alpha1.InvalidBootstrapDataReason
+	default:
+		return ""
+	}
+}
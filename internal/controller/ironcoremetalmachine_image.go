@@ -0,0 +1,347 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/image"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+var (
+	// errNoImage is returned if no image is set for an IroncoreMetalMachine.
+	errNoImage = errors.New("no image")
+	// errImageCatalogNotFound is returned if the IroncoreMetalImageCatalog of an IroncoreMetalMachine does not exist.
+	errImageCatalogNotFound = errors.New("image catalog not found")
+	// errInvalidImage is returned if the image of an IroncoreMetalMachine is not a valid image reference.
+	errInvalidImage = errors.New("invalid image")
+	// errImagePullSecretNotFound is returned if the image pull Secret of an IroncoreMetalMachine does not exist or
+	// has no docker config.
+	errImagePullSecretNotFound = errors.New("image pull secret not found")
+
+	// defaultRegistryClient is used if the reconciler has no registry client configured.
+	defaultRegistryClient = &registry.Client{}
+)
+
+// reconcileImage resolves, pins and verifies the image of the IroncoreMetalMachine for the Server and returns it.
+// The image is empty if it depends on the architecture of the Server and server is nil. bound tells whether the
+// ServerClaim is bound to the Server already. ok is false if the IroncoreMetalMachine can not be provisioned with
+// its image.
+func (r *IroncoreMetalMachineReconciler) reconcileImage(ctx context.Context, machineScope *scope.MachineScope, server *metalv1alpha1.Server, bound bool) (string, bool, error) {
+	// Resolve the image first, a version missing in the image catalog is not worth rendering the ignition for.
+	bootImage, catalog, err := r.resolveImage(ctx, machineScope, server)
+	if reason := imageFailureReason(err); reason != "" {
+		machineScope.Error(err, "failed to resolve image")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageResolvedCondition, reason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		return "", false, nil
+	}
+	if err != nil {
+		machineScope.Error(err, "failed to resolve image")
+		return "", false, err
+	}
+
+	if bootImage == "" {
+		machineScope.Info("Image depends on the architecture of the Server, waiting for the Server to be chosen")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageResolvedCondition, infrav1alpha1.WaitingForServerArchitectureReason, clusterapiv1beta1.ConditionSeverityInfo, "the image is chosen by the architecture of the Server the ServerClaim is pinned to")
+		return "", true, nil
+	}
+
+	pullSecret, err := r.getImagePullSecret(ctx, machineScope)
+	if errors.Is(err, errImagePullSecretNotFound) {
+		machineScope.Error(err, "failed to get image pull secret")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageResolvedCondition, infrav1alpha1.ImagePullSecretNotFoundReason, clusterapiv1beta1.ConditionSeverityWarning, "%s", err.Error())
+		return "", false, nil
+	}
+	if err != nil {
+		machineScope.Error(err, "failed to get image pull secret")
+		return "", false, err
+	}
+	registryClient, err := r.imageRegistryClient(pullSecret)
+	if err != nil {
+		machineScope.Error(err, "invalid image pull secret")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageResolvedCondition, infrav1alpha1.ImagePullSecretNotFoundReason, clusterapiv1beta1.ConditionSeverityWarning, "%s", err.Error())
+		return "", false, nil
+	}
+	reconcileImagePullSecretSupport(machineScope.IroncoreMetalMachine, pullSecret)
+
+	// Pin the image to its digest, so that all servers of the IroncoreMetalMachine boot the same bits.
+	bootImage, err = r.pinImage(ctx, machineScope, registryClient, bootImage, bound)
+	if reason := imageFailureReason(err); reason != "" {
+		machineScope.Error(err, "failed to pin image")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageResolvedCondition, reason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		return "", false, nil
+	}
+	if err != nil {
+		machineScope.Error(err, "failed to pin image")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageResolvedCondition, infrav1alpha1.ImageDigestUnresolvedReason, clusterapiv1beta1.ConditionSeverityWarning, "%s", err.Error())
+		return "", false, err
+	}
+	conditions.MarkTrue(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageResolvedCondition)
+
+	// Untrusted images must not be booted, not even after the configuration changed.
+	err = r.verifyImage(ctx, machineScope, registryClient, bootImage, catalog)
+	if errors.Is(err, image.ErrInvalidPublicKey) {
+		// Not terminal, the watches of the IroncoreMetalCluster and the IroncoreMetalImageCatalog pick up corrected keys.
+		machineScope.Error(err, "failed to verify image")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageVerifiedCondition, infrav1alpha1.InvalidPublicKeyReason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		return "", false, nil
+	}
+	if errors.Is(err, image.ErrUntrusted) {
+		machineScope.Error(err, "failed to verify image")
+		conditions.MarkFalse(machineScope.IroncoreMetalMachine, infrav1alpha1.ImageVerifiedCondition, infrav1alpha1.ImageVerificationFailedReason, clusterapiv1beta1.ConditionSeverityError, "%s", err.Error())
+		machineScope.SetFailureReason(infrav1alpha1.ImageVerificationFailedReason)
+		machineScope.SetFailureMessage(err)
+		return "", false, nil
+	}
+	if err != nil {
+		machineScope.Error(err, "failed to verify image")
+		return "", false, err
+	}
+	return bootImage, true, nil
+}
+
+// imageDependsOnArchitecture reports whether the image is chosen by the architecture of the Server.
+func imageDependsOnArchitecture(settings infrav1alpha1.MachineSettings) bool {
+	return settings.Image == "" && len(settings.Images) > 0
+}
+
+// resolveImage returns the image of the IroncoreMetalMachine, which is either set explicitly or by its defaults, chosen by the
+// architecture of the Server or resolved from its image catalog by the Kubernetes version of the Machine.
+// The catalog is nil if the image is not resolved from it. The image is empty if it is chosen by the architecture
+// of the Server and server is nil.
+func (r *IroncoreMetalMachineReconciler) resolveImage(ctx context.Context, machineScope *scope.MachineScope, server *metalv1alpha1.Server) (string, *infrav1alpha1.IroncoreMetalImageCatalog, error) {
+	settings := machineScope.Settings
+	if settings.Image != "" {
+		return settings.Image, nil, nil
+	}
+	if len(settings.Images) > 0 {
+		if server == nil {
+			return "", nil, nil
+		}
+		ref, err := image.ForArchitecture(settings.Images, placement.ServerArchitecture(server))
+		return ref, nil, err
+	}
+	if settings.ImageCatalogRef == nil {
+		return "", nil, fmt.Errorf("%w: neither image, images nor imageCatalogRef is set on the IroncoreMetalMachine, its class or its defaults", errNoImage)
+	}
+
+	catalog := &infrav1alpha1.IroncoreMetalImageCatalog{}
+	if err := r.Get(ctx, client.ObjectKey{Name: settings.ImageCatalogRef.Name}, catalog); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil, fmt.Errorf("%w: IroncoreMetalImageCatalog %s does not exist", errImageCatalogNotFound, settings.ImageCatalogRef.Name)
+		}
+		return "", nil, fmt.Errorf("failed to get IroncoreMetalImageCatalog: %w", err)
+	}
+	if machineScope.Machine.Spec.Version == nil {
+		return "", nil, fmt.Errorf("%w: Machine %s has no Kubernetes version", image.ErrNotInCatalog, machineScope.Machine.Name)
+	}
+	ref, err := image.FromCatalog(catalog, *machineScope.Machine.Spec.Version, machineArchitecture(settings))
+	return ref, catalog, err
+}
+
+// pinImage returns the image pinned to its digest. The digest is resolved once when the image changes and is
+// recorded in the status together with the image, so that re-pushed tags do not change the bits of provisioned
+// and later provisioned servers. Once the ServerClaim is bound, the recorded image is kept, because the Server
+// was provisioned with it.
+func (r *IroncoreMetalMachineReconciler) pinImage(ctx context.Context, machineScope *scope.MachineScope, registryClient *registry.Client, ref string, bound bool) (string, error) {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	if ironcoremetalmachine.Status.ImageDigest != "" && (ironcoremetalmachine.Status.Image == ref || bound) {
+		return image.Pin(ironcoremetalmachine.Status.Image, ironcoremetalmachine.Status.ImageDigest), nil
+	}
+
+	parsed, err := registry.ParseReference(ref)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errInvalidImage, err)
+	}
+	digest, err := registryClient.Resolve(ctx, parsed)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of image %s: %w", ref, err)
+	}
+	machineScope.Info("Pinned image to digest", "Image", ref, "Digest", digest)
+	ironcoremetalmachine.Status.Image = ref
+	ironcoremetalmachine.Status.ImageDigest = digest
+	// The signature of the previous digest says nothing about the new one.
+	conditions.Delete(ironcoremetalmachine, infrav1alpha1.ImageVerifiedCondition)
+	return image.Pin(ref, digest), nil
+}
+
+// verifyImage verifies the signature of the pinned image if public keys are configured in the IroncoreMetalCluster
+// or the image catalog. The image is only verified again when its digest changes.
+func (r *IroncoreMetalMachineReconciler) verifyImage(ctx context.Context, machineScope *scope.MachineScope, registryClient *registry.Client, ref string, catalog *infrav1alpha1.IroncoreMetalImageCatalog) error {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	publicKeys := imageVerificationKeys(machineScope.IroncoreMetalCluster, catalog)
+	if len(publicKeys) == 0 {
+		ironcoremetalmachine.Status.ImageVerificationHash = ""
+		conditions.Delete(ironcoremetalmachine, infrav1alpha1.ImageVerifiedCondition)
+		return nil
+	}
+	// The signature is verified again once the image or the trusted keys change.
+	hash := imageVerificationHash(ref, publicKeys)
+	if conditions.IsTrue(ironcoremetalmachine, infrav1alpha1.ImageVerifiedCondition) && ironcoremetalmachine.Status.ImageVerificationHash == hash {
+		return nil
+	}
+
+	digest, err := image.Verify(ctx, registryClient, ref, publicKeys)
+	if err != nil {
+		return err
+	}
+	machineScope.Info("Verified image signature", "Image", ref, "Digest", digest)
+	ironcoremetalmachine.Status.ImageVerificationHash = hash
+	conditions.MarkTrue(ironcoremetalmachine, infrav1alpha1.ImageVerifiedCondition)
+	return nil
+}
+
+// imageVerificationHash returns the hash of the image and the public keys its signature is verified with.
+func imageVerificationHash(ref string, publicKeys []string) string {
+	h := sha256.New()
+	h.Write([]byte(ref))
+	for _, publicKey := range publicKeys {
+		h.Write([]byte{0})
+		h.Write([]byte(publicKey))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// getImagePullSecret returns the image pull Secret of the IroncoreMetalMachine, or of its IroncoreMetalCluster if
+// the IroncoreMetalMachine references none. It returns nil if neither references a Secret.
+func (r *IroncoreMetalMachineReconciler) getImagePullSecret(ctx context.Context, machineScope *scope.MachineScope) (*corev1.Secret, error) {
+	ref := machineScope.Settings.ImagePullSecretRef
+	if ref == nil {
+		ref = machineScope.IroncoreMetalCluster.Spec.ImagePullSecretRef
+	}
+	if ref == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: machineScope.IroncoreMetalMachine.Namespace, Name: ref.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: Secret %s does not exist", errImagePullSecretNotFound, ref.Name)
+		}
+		return nil, fmt.Errorf("failed to get image pull Secret: %w", err)
+	}
+	if _, ok := secret.Data[corev1.DockerConfigJsonKey]; !ok {
+		return nil, fmt.Errorf("%w: Secret %s has no key %s", errImagePullSecretNotFound, ref.Name, corev1.DockerConfigJsonKey)
+	}
+	return secret, nil
+}
+
+// reconcileImagePullSecretSupport reports the image pull Secret of the IroncoreMetalMachine as unsupported by the boot
+// flow, which pulls the image without credentials.
+func reconcileImagePullSecretSupport(ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, pullSecret *corev1.Secret) {
+	if pullSecret == nil {
+		conditions.Delete(ironcoremetalmachine, infrav1alpha1.ImagePullSecretUnsupportedCondition)
+		return
+	}
+	conditions.Set(ironcoremetalmachine, &clusterapiv1beta1.Condition{
+		Type:     infrav1alpha1.ImagePullSecretUnsupportedCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterapiv1beta1.ConditionSeverityWarning,
+		Reason:   infrav1alpha1.ImagePullSecretUnsupportedReason,
+		Message: fmt.Sprintf("Secret %s is only used to resolve and verify the image, the boot flow pulls it without credentials",
+			pullSecret.Name),
+	})
+}
+
+// imageRegistryClient returns the registry client using the credentials of the image pull Secret, if any.
+func (r *IroncoreMetalMachineReconciler) imageRegistryClient(pullSecret *corev1.Secret) (*registry.Client, error) {
+	if pullSecret == nil {
+		return r.registryClient(), nil
+	}
+	config, err := registry.ParseDockerConfig(pullSecret.Data[corev1.DockerConfigJsonKey])
+	if err != nil {
+		return nil, fmt.Errorf("image pull Secret %s: %w", pullSecret.Name, err)
+	}
+	return r.registryClient().WithKeychain(config), nil
+}
+
+func (r *IroncoreMetalMachineReconciler) registryClient() *registry.Client {
+	if r.Registry == nil {
+		return defaultRegistryClient
+	}
+	return r.Registry
+}
+
+// machineArchitecture returns the CPU architecture of the servers the IroncoreMetalMachine selects.
+func machineArchitecture(settings infrav1alpha1.MachineSettings) string {
+	if selector := settings.ServerSelector; selector != nil {
+		if arch := selector.MatchLabels[infrav1alpha1.ArchitectureLabel]; arch != "" {
+			return arch
+		}
+	}
+	return infrav1alpha1.DefaultArchitecture
+}
+
+// imageVerificationKeys returns the public keys images are verified with, which are the keys of the
+// IroncoreMetalCluster and of the image catalog the image was resolved from.
+func imageVerificationKeys(ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster, catalog *infrav1alpha1.IroncoreMetalImageCatalog) []string {
+	var publicKeys []string
+	if verification := ironcoremetalcluster.Spec.ImageVerification; verification != nil {
+		publicKeys = append(publicKeys, verification.PublicKeys...)
+	}
+	if catalog != nil && catalog.Spec.ImageVerification != nil {
+		publicKeys = append(publicKeys, catalog.Spec.ImageVerification.PublicKeys...)
+	}
+	return publicKeys
+}
+
+// imageFailureReason returns the reason of the ImageResolved condition for an error resolving the image,
+// or an empty string if the error is transient.
+func imageFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errNoImage):
+		return infrav1alpha1.NoImageReason
+	case errors.Is(err, errImageCatalogNotFound):
+		return infrav1alpha1.ImageCatalogNotFoundReason
+	case errors.Is(err, image.ErrNotInCatalog):
+		return infrav1alpha1.ImageNotInCatalogReason
+	case errors.Is(err, image.ErrUnsupportedArchitecture):
+		return infrav1alpha1.UnsupportedArchitectureReason
+	case errors.Is(err, errInvalidImage):
+		return infrav1alpha1.InvalidImageReason
+	default:
+		return ""
+	}
+}
+
+// imageCatalogToIroncoreMetalMachines enqueues the IroncoreMetalMachines referencing the IroncoreMetalImageCatalog,
+// so that images added to the catalog are picked up.
+func (r *IroncoreMetalMachineReconciler) imageCatalogToIroncoreMetalMachines(ctx context.Context, obj client.Object) []reconcile.Request {
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := r.List(ctx, machineList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalMachines")
+		return nil
+	}
+
+	lookup := newCandidateLookup(r.Client)
+	var requests []reconcile.Request
+	for i := range machineList.Items {
+		settings, err := effectiveSettings(ctx, lookup, &machineList.Items[i])
+		if err != nil {
+			log.FromContext(ctx).Error(err, "failed to resolve the settings of IroncoreMetalMachine", "IroncoreMetalMachine", client.ObjectKeyFromObject(&machineList.Items[i]))
+			continue
+		}
+		if ref := settings.ImageCatalogRef; ref != nil && ref.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&machineList.Items[i])})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var (
+	// errMachineClassNotFound is returned if the IroncoreMetalMachineClass of an IroncoreMetalMachine does not exist.
+	errMachineClassNotFound = errors.New("machine class not found")
+)
+
+// getMachineClass returns the IroncoreMetalMachineClass of the IroncoreMetalMachine, or nil if it has none.
+func (r *IroncoreMetalMachineReconciler) getMachineClass(ctx context.Context, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine) (*infrav1alpha1.IroncoreMetalMachineClass, error) {
+	if ironcoremetalmachine.Spec.Class == "" {
+		return nil, nil
+	}
+	class := &infrav1alpha1.IroncoreMetalMachineClass{}
+	if err := r.Get(ctx, client.ObjectKey{Name: ironcoremetalmachine.Spec.Class}, class); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: IroncoreMetalMachineClass %s does not exist", errMachineClassNotFound, ironcoremetalmachine.Spec.Class)
+		}
+		return nil, fmt.Errorf("failed to get IroncoreMetalMachineClass: %w", err)
+	}
+	return class, nil
+}
+
+// reconcileMachineClassHash records the hash of the IroncoreMetalMachineClass as long as the settings of the
+// IroncoreMetalMachine follow the class, i.e. until its ServerClaim exists. Later changes of the class are reported by
+// the MachineClassUpToDate condition, because the settings of the machine are kept.
+func reconcileMachineClassHash(machineScope *scope.MachineScope) error {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	class := machineScope.IroncoreMetalMachineClass
+	if class == nil {
+		ironcoremetalmachine.Status.MachineClassHash = ""
+		conditions.Delete(ironcoremetalmachine, infrav1alpha1.MachineClassUpToDateCondition)
+		return nil
+	}
+
+	hash, err := machineClassHash(class)
+	if err != nil {
+		return err
+	}
+	if !machineScope.SettingsFrozen() || ironcoremetalmachine.Status.MachineClassHash == "" {
+		ironcoremetalmachine.Status.MachineClassHash = hash
+	}
+	if ironcoremetalmachine.Status.MachineClassHash != hash {
+		conditions.MarkFalse(ironcoremetalmachine, infrav1alpha1.MachineClassUpToDateCondition, infrav1alpha1.MachineClassChangedReason, clusterapiv1beta1.ConditionSeverityWarning,
+			"IroncoreMetalMachineClass %s changed after the ServerClaim of the IroncoreMetalMachine was created", class.Name)
+		return nil
+	}
+	conditions.MarkTrue(ironcoremetalmachine, infrav1alpha1.MachineClassUpToDateCondition)
+	return nil
+}
+
+// machineClassHash returns the hash of the spec of the IroncoreMetalMachineClass.
+func machineClassHash(class *infrav1alpha1.IroncoreMetalMachineClass) (string, error) {
+	data, err := json.Marshal(class.Spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal IroncoreMetalMachineClass %s: %w", class.Name, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// machineClassToIroncoreMetalMachines enqueues the IroncoreMetalMachines of the IroncoreMetalMachineClass, so that
+// changes of the class are picked up by machines which are not provisioned yet and reported by the others.
+func (r *IroncoreMetalMachineReconciler) machineClassToIroncoreMetalMachines(ctx context.Context, obj client.Object) []reconcile.Request {
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := r.List(ctx, machineList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalMachines")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range machineList.Items {
+		if machineList.Items[i].Spec.Class == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&machineList.Items[i])})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,351 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/image"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+var (
+	// errNoMatchingServer is returned if no Server matches the requirements of an IroncoreMetalMachine.
+	errNoMatchingServer = errors.New("no matching server")
+	// errWaitingForServers is returned if Servers match the requirements of an IroncoreMetalMachine, but none of
+	// them is available.
+	errWaitingForServers = errors.New("waiting for servers")
+)
+
+// placeServerClaim checks that an available Server matches the IroncoreMetalMachine before its ServerClaim is
+// created, and chooses the Server the ServerClaim is pinned to if the IroncoreMetalMachine has requirements which
+// the ServerSelector can not express. It returns the Server the existing ServerClaim is pinned to, and nil if the
+// ServerClaim selects its Server itself.
+func (r *IroncoreMetalMachineReconciler) placeServerClaim(ctx context.Context, machineScope *scope.MachineScope, serverSelector *metav1.LabelSelector) (*corev1.LocalObjectReference, error) {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	ironcoremetalmachine.Status.QueuePosition = nil
+	claimObj := &metalv1alpha1.ServerClaim{}
+	if err := r.apiReader().Get(ctx, client.ObjectKeyFromObject(ironcoremetalmachine), claimObj); err == nil {
+		return r.checkPinnedServer(ctx, machineScope, claimObj)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get ServerClaim: %w", err)
+	}
+
+	serverList := &metalv1alpha1.ServerList{}
+	if err := r.List(ctx, serverList); err != nil {
+		return nil, fmt.Errorf("failed to list Servers: %w", err)
+	}
+	// The ServerRef of a ServerClaim is immutable, hence the ServerClaims are listed from the API server to not pin
+	// a Server a ServerClaim created since the last cache update already references.
+	claimList := &metalv1alpha1.ServerClaimList{}
+	if err := r.apiReader().List(ctx, claimList); err != nil {
+		return nil, fmt.Errorf("failed to list ServerClaims: %w", err)
+	}
+	request, err := r.placementRequest(ctx, machineScope, serverSelector, serverList.Items, claimList.Items)
+	if err != nil {
+		return nil, err
+	}
+	claimed := claimedServers(claimList.Items)
+
+	// Anti-affinity depends on the siblings placed so far, the Servers of the MachineDeployment do not.
+	deploymentRequest := request
+	deploymentRequest.AntiAffinity = nil
+	if err := r.checkMachineDeploymentFits(ctx, machineScope, placement.Count(serverList.Items, claimed, deploymentRequest)); err != nil {
+		return nil, err
+	}
+
+	exhausted, err := r.checkQuota(ctx, machineScope, serverList.Items, claimList.Items)
+	if err != nil {
+		return nil, err
+	}
+	var exhaustedNames []string
+	for _, class := range exhausted {
+		selector, err := metav1.LabelSelectorAsSelector(&class.ServerSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ServerSelector of quota class %s: %w", class.Name, err)
+		}
+		request.Excluded = append(request.Excluded, selector)
+		exhaustedNames = append(exhaustedNames, class.Name)
+	}
+
+	availability := placement.Count(serverList.Items, claimed, request)
+	if availability.Available == 0 && len(exhausted) > 0 && placement.Count(serverList.Items, claimed, deploymentRequest).Available > 0 {
+		return nil, fmt.Errorf("%w: the Servers of quota classes %s are all used", errQuotaExceeded, strings.Join(exhaustedNames, ", "))
+	}
+	if availability.Matching == 0 {
+		return nil, fmt.Errorf("%w: none of the %d Servers matches", errNoMatchingServer, len(serverList.Items))
+	}
+	if availability.Available == 0 {
+		return nil, fmt.Errorf("%w: %d Servers match, %d are available, %d are claimed",
+			errWaitingForServers, availability.Matching, availability.Available, availability.Claimed)
+	}
+
+	position, err := r.queuePosition(ctx, machineScope, request, serverList.Items, claimed, claimList.Items)
+	if err != nil {
+		return nil, err
+	}
+	if position > availability.Available {
+		ironcoremetalmachine.Status.QueuePosition = ptr.To(int32(position))
+		return nil, fmt.Errorf("%w: position %d in the queue for %d available Servers", errQueued, position, availability.Available)
+	}
+	// ServerClaims which are not pinned could be bound to Servers of pools or of exhausted quota classes. They also
+	// only count against quota classes once metal-operator bound them, so that a burst of machines could exceed them.
+	if !pinsServerClaim(ironcoremetalmachine, machineScope.Settings) && len(request.Excluded) == 0 && !hasQuotaClasses(machineScope.IroncoreMetalCluster) {
+		return nil, nil
+	}
+
+	for {
+		server, score := placement.Select(serverList.Items, claimed, request)
+		if server == nil {
+			return nil, fmt.Errorf("%w: the available Servers were claimed in the meantime", errWaitingForServers)
+		}
+		current := &metalv1alpha1.Server{}
+		if err := r.apiReader().Get(ctx, client.ObjectKeyFromObject(server), current); client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("failed to get Server %s: %w", server.Name, err)
+		} else if err != nil || !placement.Claimable(current, claimed) {
+			claimed.Insert(server.Name)
+			continue
+		}
+		machineScope.Info("Chose Server for ServerClaim", "Server", server.Name, "Score", score)
+		ironcoremetalmachine.Status.Placement = &infrav1alpha1.PlacementStatus{Server: server.Name, Score: score}
+		return &corev1.LocalObjectReference{Name: server.Name}, nil
+	}
+}
+
+// checkPinnedServer returns the Server the existing ServerClaim is pinned to. The ServerRef of a ServerClaim is
+// immutable and metal-operator never binds a ServerClaim to a Server another ServerClaim claimed, hence an unbound
+// ServerClaim whose Server was taken or deleted is deleted to be recreated with another Server.
+func (r *IroncoreMetalMachineReconciler) checkPinnedServer(ctx context.Context, machineScope *scope.MachineScope, claimObj *metalv1alpha1.ServerClaim) (*corev1.LocalObjectReference, error) {
+	if !claimObj.DeletionTimestamp.IsZero() {
+		return nil, fmt.Errorf("%w: ServerClaim %s is being deleted", errWaitingForServers, claimObj.Name)
+	}
+	if claimObj.Spec.ServerRef == nil || claimObj.Status.Phase == metalv1alpha1.PhaseBound {
+		return claimObj.Spec.ServerRef, nil
+	}
+
+	server := &metalv1alpha1.Server{}
+	err := r.apiReader().Get(ctx, client.ObjectKey{Name: claimObj.Spec.ServerRef.Name}, server)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("failed to get Server %s: %w", claimObj.Spec.ServerRef.Name, err)
+	}
+	if err == nil && !claimedByOther(server, claimObj) {
+		return claimObj.Spec.ServerRef, nil
+	}
+
+	machineScope.Info("Recreating ServerClaim, its Server is gone or claimed by another ServerClaim", "Server", claimObj.Spec.ServerRef.Name)
+	if err := r.Delete(ctx, claimObj); client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("failed to delete ServerClaim: %w", err)
+	}
+	machineScope.IroncoreMetalMachine.Status.Placement = nil
+	return nil, fmt.Errorf("%w: Server %s was claimed by another ServerClaim", errWaitingForServers, claimObj.Spec.ServerRef.Name)
+}
+
+// claimedByOther reports whether the Server is claimed by another ServerClaim than the given one.
+func claimedByOther(server *metalv1alpha1.Server, claimObj *metalv1alpha1.ServerClaim) bool {
+	ref := server.Spec.ServerClaimRef
+	if ref == nil {
+		return false
+	}
+	if ref.UID != "" {
+		return ref.UID != claimObj.UID
+	}
+	return ref.Namespace != claimObj.Namespace || ref.Name != claimObj.Name
+}
+
+// checkMachineDeploymentFits emits a warning event on the MachineDeployment of the Machine when it gets more replicas
+// than Servers match, so that it can never be fully provisioned.
+func (r *IroncoreMetalMachineReconciler) checkMachineDeploymentFits(ctx context.Context, machineScope *scope.MachineScope, pool placement.Availability) error {
+	deploymentName, ok := machineScope.Machine.Labels[clusterapiv1beta1.MachineDeploymentNameLabel]
+	if !ok {
+		return nil
+	}
+	deployment := &clusterapiv1beta1.MachineDeployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: machineScope.Machine.Namespace, Name: deploymentName}, deployment); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	key := client.ObjectKeyFromObject(deployment)
+	replicas := ptr.Deref(deployment.Spec.Replicas, 0)
+	if int(replicas) <= pool.Matching {
+		r.insufficientDeployments.Delete(key)
+		return nil
+	}
+	if matching, ok := r.insufficientDeployments.Swap(key, pool.Matching); !ok || matching != pool.Matching {
+		record.Warnf(deployment, "InsufficientServers",
+			"MachineDeployment has %d replicas, but only %d Servers match its IroncoreMetalMachines", replicas, pool.Matching)
+	}
+	return nil
+}
+
+// placementRequest returns the placement request of the Servers the IroncoreMetalMachine can be placed on. The
+// anti-affinity terms stay out of the topology domains of the Servers the ServerClaims of its siblings reference.
+func (r *IroncoreMetalMachineReconciler) placementRequest(ctx context.Context, machineScope *scope.MachineScope, serverSelector *metav1.LabelSelector, servers []metalv1alpha1.Server, claims []metalv1alpha1.ServerClaim) (placement.Request, error) {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	request := placement.Request{
+		FirmwarePolicy: machineScope.Settings.FirmwarePolicy,
+		BIOSVersion:    biosVersion(machineScope.Settings),
+	}
+	if requiresBMCFirmware(request.FirmwarePolicy) {
+		var err error
+		if request.BMCFirmware, err = bmcFirmware(ctx, r.Client); err != nil {
+			return placement.Request{}, err
+		}
+	}
+	if imageDependsOnArchitecture(machineScope.Settings) {
+		request.Architectures = sets.New(image.Architectures(machineScope.Settings.Images)...)
+	}
+	if ironcoremetalmachine.Spec.PoolRef == nil {
+		poolList := &infrav1alpha1.IroncoreMetalServerPoolList{}
+		if err := r.List(ctx, poolList); err != nil {
+			return placement.Request{}, fmt.Errorf("failed to list IroncoreMetalServerPools: %w", err)
+		}
+		var err error
+		if request.Excluded, err = pooledServerSelectors(poolList.Items); err != nil {
+			return placement.Request{}, err
+		}
+	}
+	if serverSelector != nil {
+		var err error
+		if request.Selector, err = metav1.LabelSelectorAsSelector(serverSelector); err != nil {
+			return placement.Request{}, fmt.Errorf("invalid ServerSelector: %w", err)
+		}
+	}
+	for i, term := range ironcoremetalmachine.Spec.PreferredServers {
+		selector, err := metav1.LabelSelectorAsSelector(&term.Preference)
+		if err != nil {
+			return placement.Request{}, fmt.Errorf("invalid preference of PreferredServers[%d]: %w", i, err)
+		}
+		request.Preferences = append(request.Preferences, placement.Preference{Weight: term.Weight, Selector: selector})
+	}
+
+	serversByName := make(map[string]*metalv1alpha1.Server, len(servers))
+	for i := range servers {
+		serversByName[servers[i].Name] = &servers[i]
+	}
+	claimedBy := make(map[client.ObjectKey]string, len(claims))
+	for _, claim := range claims {
+		if claim.Spec.ServerRef != nil {
+			claimedBy[client.ObjectKeyFromObject(&claim)] = claim.Spec.ServerRef.Name
+		}
+	}
+
+	for _, term := range ironcoremetalmachine.Spec.ServerAntiAffinity {
+		siblings, err := r.siblingMachineNames(ctx, machineScope.Machine, term.Scope)
+		if err != nil {
+			return placement.Request{}, err
+		}
+		domains := sets.New[string]()
+		for _, sibling := range siblings {
+			server, ok := serversByName[claimedBy[client.ObjectKey{Namespace: ironcoremetalmachine.Namespace, Name: sibling}]]
+			if !ok {
+				continue
+			}
+			if domain, ok := server.Labels[term.TopologyKey]; ok {
+				domains.Insert(domain)
+			}
+		}
+		request.AntiAffinity = append(request.AntiAffinity, placement.AntiAffinityTerm{
+			TopologyKey: term.TopologyKey,
+			Required:    term.Mode != infrav1alpha1.AntiAffinityModePreferred,
+			Domains:     domains,
+		})
+	}
+	return request, nil
+}
+
+// siblingMachineNames returns the names of the IroncoreMetalMachines of the Machines in the scope of the Machine,
+// not including the Machine itself.
+func (r *IroncoreMetalMachineReconciler) siblingMachineNames(ctx context.Context, machine *clusterapiv1beta1.Machine, antiAffinityScope infrav1alpha1.AntiAffinityScope) ([]string, error) {
+	matchingLabels := client.MatchingLabels{clusterapiv1beta1.ClusterNameLabel: machine.Spec.ClusterName}
+	listOptions := []client.ListOption{client.InNamespace(machine.Namespace), matchingLabels}
+	switch antiAffinityScope {
+	case infrav1alpha1.AntiAffinityScopeMachineDeployment:
+		deployment, ok := machine.Labels[clusterapiv1beta1.MachineDeploymentNameLabel]
+		if !ok {
+			return nil, nil
+		}
+		matchingLabels[clusterapiv1beta1.MachineDeploymentNameLabel] = deployment
+	case infrav1alpha1.AntiAffinityScopeControlPlane:
+		listOptions = append(listOptions, client.HasLabels{clusterapiv1beta1.MachineControlPlaneLabel})
+	}
+
+	machineList := &clusterapiv1beta1.MachineList{}
+	if err := r.List(ctx, machineList, listOptions...); err != nil {
+		return nil, fmt.Errorf("failed to list sibling Machines: %w", err)
+	}
+	var names []string
+	for _, sibling := range machineList.Items {
+		if sibling.Name == machine.Name || sibling.Spec.InfrastructureRef.Kind != "IroncoreMetalMachine" {
+			continue
+		}
+		names = append(names, sibling.Spec.InfrastructureRef.Name)
+	}
+	return names, nil
+}
+
+// clusterServerClaims returns the ServerClaims of the cluster, which are labeled with the cluster or named like the
+// IroncoreMetalMachines of the cluster.
+func clusterServerClaims(ctx context.Context, c client.Client, namespace, clusterName string, claims []metalv1alpha1.ServerClaim) ([]metalv1alpha1.ServerClaim, error) {
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := c.List(ctx, machineList, client.InNamespace(namespace), client.MatchingLabels{clusterapiv1beta1.ClusterNameLabel: clusterName}); err != nil {
+		return nil, fmt.Errorf("failed to list IroncoreMetalMachines of cluster %s: %w", clusterName, err)
+	}
+	names := sets.New[string]()
+	for _, machine := range machineList.Items {
+		names.Insert(machine.Name)
+	}
+
+	var clusterClaims []metalv1alpha1.ServerClaim
+	for _, claim := range claims {
+		if claim.Namespace == namespace && (claim.Labels[clusterapiv1beta1.ClusterNameLabel] == clusterName || names.Has(claim.Name)) {
+			clusterClaims = append(clusterClaims, claim)
+		}
+	}
+	return clusterClaims, nil
+}
+
+// claimedServers returns the names of the Servers referenced by the ServerClaims, which are claimed even if the
+// Servers do not reference their ServerClaims yet.
+func claimedServers(claims []metalv1alpha1.ServerClaim) sets.Set[string] {
+	claimed := sets.New[string]()
+	for _, claim := range claims {
+		if claim.Spec.ServerRef != nil {
+			claimed.Insert(claim.Spec.ServerRef.Name)
+		}
+	}
+	return claimed
+}
+
+// pinsServerClaim reports whether the ServerClaim of the IroncoreMetalMachine is pinned to a Server chosen by
+// the controller instead of selecting its Server itself. Machines with images per architecture are pinned, because
+// the image has to be chosen by the architecture of the Server before the ServerClaim is created.
+func pinsServerClaim(ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, settings infrav1alpha1.MachineSettings) bool {
+	spec := ironcoremetalmachine.Spec
+	return settings.FirmwarePolicy != nil || len(spec.ServerAntiAffinity) > 0 || len(spec.PreferredServers) > 0 ||
+		imageDependsOnArchitecture(settings)
+}
+
+// claimServerSelector returns the ServerSelector of the ServerClaim of the IroncoreMetalMachine. It only selects the
+// Servers the selector of the cluster selects and the Servers of the pool if one is given.
+func claimServerSelector(settings infrav1alpha1.MachineSettings, clusterSelector *metav1.LabelSelector, pool *infrav1alpha1.IroncoreMetalServerPool) *metav1.LabelSelector {
+	var poolSelector *metav1.LabelSelector
+	if pool != nil {
+		poolSelector = &pool.Spec.ServerSelector
+	}
+	return placement.AndSelectors(clusterSelector, poolSelector, settings.ServerSelector)
+}
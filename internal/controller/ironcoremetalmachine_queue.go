@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+var (
+	// errQueued is returned if IroncoreMetalMachines ahead in the queue wait for the Servers an IroncoreMetalMachine matches.
+	errQueued = errors.New("queued")
+)
+
+// queuePosition returns the position of the IroncoreMetalMachine among the machines waiting for the Servers it
+// matches. The machines are admitted in the order of their positions when Servers are scarce.
+func (r *IroncoreMetalMachineReconciler) queuePosition(ctx context.Context, machineScope *scope.MachineScope, request placement.Request, servers []metalv1alpha1.Server, claimed sets.Set[string], claims []metalv1alpha1.ServerClaim) (int, error) {
+	ironcoremetalmachine := machineScope.IroncoreMetalMachine
+	waiting, err := r.waitingCandidates(ctx, claims)
+	if err != nil {
+		return 0, err
+	}
+	candidate := placement.Candidate{
+		Name:              client.ObjectKeyFromObject(ironcoremetalmachine),
+		ControlPlane:      isControlPlane(ironcoremetalmachine),
+		Priority:          machinePriority(ironcoremetalmachine, machineScope.IroncoreMetalCluster.Spec.Priority),
+		CreationTimestamp: ironcoremetalmachine.CreationTimestamp,
+		Request:           request,
+	}
+	return placement.QueuePosition(candidate, waiting, servers, claimed), nil
+}
+
+// waitingCandidates returns the IroncoreMetalMachines without ServerClaim which only wait for the Servers they match.
+// Only the clusters, pools and classes of these machines are read.
+func (r *IroncoreMetalMachineReconciler) waitingCandidates(ctx context.Context, claims []metalv1alpha1.ServerClaim) ([]placement.Candidate, error) {
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := r.List(ctx, machineList, client.MatchingFields{ironcoreMetalMachineUnplacedField: "true"}); err != nil {
+		return nil, fmt.Errorf("failed to list IroncoreMetalMachines: %w", err)
+	}
+
+	hasClaim := sets.New[client.ObjectKey]()
+	for i := range claims {
+		hasClaim.Insert(client.ObjectKeyFromObject(&claims[i]))
+	}
+	lookup := newCandidateLookup(r.Client)
+
+	var waiting []placement.Candidate
+	for i := range machineList.Items {
+		machine := &machineList.Items[i]
+		if hasClaim.Has(client.ObjectKeyFromObject(machine)) {
+			continue
+		}
+		cluster, err := lookup.cluster(ctx, machine.Namespace, machine.Labels[clusterapiv1beta1.ClusterNameLabel])
+		if err != nil {
+			return nil, err
+		} else if cluster == nil {
+			continue
+		}
+		var pool *infrav1alpha1.IroncoreMetalServerPool
+		if machine.Spec.PoolRef != nil {
+			if pool, err = lookup.pool(ctx, machine.Spec.PoolRef.Name); err != nil {
+				return nil, err
+			} else if pool == nil {
+				continue
+			}
+		}
+		var class *infrav1alpha1.IroncoreMetalMachineClass
+		if machine.Spec.Class != "" {
+			if class, err = lookup.class(ctx, machine.Spec.Class); err != nil {
+				return nil, err
+			} else if class == nil {
+				continue
+			}
+		}
+
+		settings := scope.MachineSettings(machine, class, cluster)
+		request := placement.Request{FirmwarePolicy: settings.FirmwarePolicy, BIOSVersion: biosVersion(settings)}
+		if requiresBMCFirmware(settings.FirmwarePolicy) {
+			if request.BMCFirmware, err = lookup.bmcFirmware(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if pool == nil {
+			if request.Excluded, err = lookup.pooledServerSelectors(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if selector := claimServerSelector(settings, cluster.Spec.ServerSelector, pool); selector != nil {
+			if request.Selector, err = metav1.LabelSelectorAsSelector(selector); err != nil {
+				continue
+			}
+		}
+		waiting = append(waiting, placement.Candidate{
+			Name:              client.ObjectKeyFromObject(machine),
+			ControlPlane:      isControlPlane(machine),
+			Priority:          machinePriority(machine, cluster.Spec.Priority),
+			CreationTimestamp: machine.CreationTimestamp,
+			Request:           request,
+		})
+	}
+	return waiting, nil
+}
+
+// candidateLookup reads the objects the requests of waiting IroncoreMetalMachines depend on once per queue.
+// Missing objects are looked up as nil.
+type candidateLookup struct {
+	client   client.Client
+	clusters map[client.ObjectKey]*infrav1alpha1.IroncoreMetalCluster
+	pools    map[string]*infrav1alpha1.IroncoreMetalServerPool
+	classes  map[string]*infrav1alpha1.IroncoreMetalMachineClass
+	pooled   []labels.Selector
+	bmcs     map[string]string
+}
+
+func newCandidateLookup(c client.Client) *candidateLookup {
+	return &candidateLookup{
+		client:   c,
+		clusters: map[client.ObjectKey]*infrav1alpha1.IroncoreMetalCluster{},
+		pools:    map[string]*infrav1alpha1.IroncoreMetalServerPool{},
+		classes:  map[string]*infrav1alpha1.IroncoreMetalMachineClass{},
+	}
+}
+
+// cluster returns the IroncoreMetalCluster of the Cluster with the name in the namespace.
+func (l *candidateLookup) cluster(ctx context.Context, namespace, clusterName string) (*infrav1alpha1.IroncoreMetalCluster, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: clusterName}
+	if cluster, ok := l.clusters[key]; ok {
+		return cluster, nil
+	}
+	clusterList := &infrav1alpha1.IroncoreMetalClusterList{}
+	if err := l.client.List(ctx, clusterList, client.InNamespace(namespace), client.MatchingLabels{clusterapiv1beta1.ClusterNameLabel: clusterName}); err != nil {
+		return nil, fmt.Errorf("failed to list IroncoreMetalClusters of cluster %s: %w", clusterName, err)
+	}
+	var cluster *infrav1alpha1.IroncoreMetalCluster
+	if len(clusterList.Items) > 0 {
+		cluster = &clusterList.Items[0]
+	}
+	l.clusters[key] = cluster
+	return cluster, nil
+}
+
+func (l *candidateLookup) pool(ctx context.Context, name string) (*infrav1alpha1.IroncoreMetalServerPool, error) {
+	if pool, ok := l.pools[name]; ok {
+		return pool, nil
+	}
+	pool := &infrav1alpha1.IroncoreMetalServerPool{}
+	if err := l.client.Get(ctx, client.ObjectKey{Name: name}, pool); client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("failed to get IroncoreMetalServerPool %s: %w", name, err)
+	} else if err != nil {
+		pool = nil
+	}
+	l.pools[name] = pool
+	return pool, nil
+}
+
+func (l *candidateLookup) class(ctx context.Context, name string) (*infrav1alpha1.IroncoreMetalMachineClass, error) {
+	if class, ok := l.classes[name]; ok {
+		return class, nil
+	}
+	class := &infrav1alpha1.IroncoreMetalMachineClass{}
+	if err := l.client.Get(ctx, client.ObjectKey{Name: name}, class); client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("failed to get IroncoreMetalMachineClass %s: %w", name, err)
+	} else if err != nil {
+		class = nil
+	}
+	l.classes[name] = class
+	return class, nil
+}
+
+// pooledServerSelectors returns the selectors of the Servers of all pools, which are only listed if a waiting
+// IroncoreMetalMachine has no pool.
+func (l *candidateLookup) pooledServerSelectors(ctx context.Context) ([]labels.Selector, error) {
+	if l.pooled != nil {
+		return l.pooled, nil
+	}
+	poolList := &infrav1alpha1.IroncoreMetalServerPoolList{}
+	if err := l.client.List(ctx, poolList); err != nil {
+		return nil, fmt.Errorf("failed to list IroncoreMetalServerPools: %w", err)
+	}
+	pooled, err := pooledServerSelectors(poolList.Items)
+	if err != nil {
+		return nil, err
+	}
+	l.pooled = pooled
+	return pooled, nil
+}
+
+// bmcFirmware returns the firmware versions of the BMCs, which are only listed if a waiting IroncoreMetalMachine has
+// a firmware policy requiring a BMC version.
+func (l *candidateLookup) bmcFirmware(ctx context.Context) (map[string]string, error) {
+	if l.bmcs != nil {
+		return l.bmcs, nil
+	}
+	bmcs, err := bmcFirmware(ctx, l.client)
+	if err != nil {
+		return nil, err
+	}
+	l.bmcs = bmcs
+	return bmcs, nil
+}
+
+// isControlPlane reports whether the IroncoreMetalMachine belongs to a control plane, which labels its
+// infrastructure machines like its Machines.
+func isControlPlane(ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine) bool {
+	_, ok := ironcoremetalmachine.Labels[clusterapiv1beta1.MachineControlPlaneLabel]
+	return ok
+}
+
+// machinePriority returns the priority of the IroncoreMetalMachine, which defaults to the priority of its cluster.
+func machinePriority(ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine, clusterPriority int32) int32 {
+	return ptr.Deref(ironcoremetalmachine.Spec.Priority, clusterPriority)
+}
+
+// effectiveSettings returns the settings the IroncoreMetalMachine was last reconciled with. The settings of a
+// machine which was not reconciled yet are inherited from its class and IroncoreMetalCluster, so that it is enqueued
+// for the objects its defaults reference as well.
+func effectiveSettings(ctx context.Context, lookup *candidateLookup, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine) (infrav1alpha1.MachineSettings, error) {
+	if settings := ironcoremetalmachine.Status.EffectiveSettings; settings != nil {
+		return *settings, nil
+	}
+	cluster, err := lookup.cluster(ctx, ironcoremetalmachine.Namespace, ironcoremetalmachine.Labels[clusterapiv1beta1.ClusterNameLabel])
+	if err != nil {
+		return infrav1alpha1.MachineSettings{}, err
+	}
+	var class *infrav1alpha1.IroncoreMetalMachineClass
+	if ironcoremetalmachine.Spec.Class != "" {
+		if class, err = lookup.class(ctx, ironcoremetalmachine.Spec.Class); err != nil {
+			return infrav1alpha1.MachineSettings{}, err
+		}
+	}
+	return scope.MachineSettings(ironcoremetalmachine, class, cluster), nil
+}
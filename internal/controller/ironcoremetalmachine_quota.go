@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+var (
+	// errQuotaExceeded is returned if the cluster of an IroncoreMetalMachine used up its quota of the Servers the
+	// IroncoreMetalMachine matches.
+	errQuotaExceeded = errors.New("quota exceeded")
+)
+
+// checkQuota returns an errQuotaExceeded error if the cluster of the IroncoreMetalMachine has as many ServerClaims
+// as its quota allows. Otherwise it returns the quota classes whose Servers are all used. The claims have to be read
+// from the API server, the cache may miss the ServerClaims just created for other IroncoreMetalMachines of a burst.
+func (r *IroncoreMetalMachineReconciler) checkQuota(ctx context.Context, machineScope *scope.MachineScope, servers []metalv1alpha1.Server, claims []metalv1alpha1.ServerClaim) ([]infrav1alpha1.ServerQuotaClass, error) {
+	quota := machineScope.IroncoreMetalCluster.Spec.Quota
+	if quota == nil {
+		return nil, nil
+	}
+	clusterClaims, err := clusterServerClaims(ctx, r.Client, machineScope.Cluster.Namespace, machineScope.Cluster.Name, claims)
+	if err != nil {
+		return nil, err
+	}
+	if quota.MaxServers != nil && len(clusterClaims) >= int(*quota.MaxServers) {
+		return nil, fmt.Errorf("%w: the cluster has %d of at most %d Servers", errQuotaExceeded, len(clusterClaims), *quota.MaxServers)
+	}
+	usage, err := placement.QuotaUsage(quota, clusterClaims, servers)
+	if err != nil {
+		return nil, err
+	}
+	return placement.ExhaustedClasses(quota, usage), nil
+}
+
+// hasQuotaClasses reports whether the quota of the IroncoreMetalCluster limits the Servers of classes.
+func hasQuotaClasses(ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster) bool {
+	return ironcoremetalcluster.Spec.Quota != nil && len(ironcoremetalcluster.Spec.Quota.Classes) > 0
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/scope"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var (
+	// errServerPoolNotFound is returned if the IroncoreMetalServerPool of an IroncoreMetalMachine does not exist.
+	errServerPoolNotFound = errors.New("server pool not found")
+	// errServerPoolNotAllowed is returned if the IroncoreMetalServerPool of an IroncoreMetalMachine does not allow its cluster.
+	errServerPoolNotAllowed = errors.New("server pool not allowed")
+)
+
+// getServerPool returns the IroncoreMetalServerPool the IroncoreMetalMachine claims its Server from, or nil if it
+// does not reference one.
+func (r *IroncoreMetalMachineReconciler) getServerPool(ctx context.Context, machineScope *scope.MachineScope) (*infrav1alpha1.IroncoreMetalServerPool, error) {
+	ref := machineScope.IroncoreMetalMachine.Spec.PoolRef
+	if ref == nil {
+		return nil, nil
+	}
+	pool := &infrav1alpha1.IroncoreMetalServerPool{}
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name}, pool); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: IroncoreMetalServerPool %s does not exist", errServerPoolNotFound, ref.Name)
+		}
+		return nil, fmt.Errorf("failed to get IroncoreMetalServerPool: %w", err)
+	}
+	if !serverPoolAllows(pool, machineScope.Cluster.Namespace, machineScope.Cluster.Name) {
+		return nil, fmt.Errorf("%w: IroncoreMetalServerPool %s does not allow Cluster %s/%s",
+			errServerPoolNotAllowed, ref.Name, machineScope.Cluster.Namespace, machineScope.Cluster.Name)
+	}
+	return pool, nil
+}
+
+// serverPoolToIroncoreMetalMachines enqueues the IroncoreMetalMachines referencing the IroncoreMetalServerPool.
+func (r *IroncoreMetalMachineReconciler) serverPoolToIroncoreMetalMachines(ctx context.Context, obj client.Object) []reconcile.Request {
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := r.List(ctx, machineList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalMachines")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range machineList.Items {
+		if ref := machineList.Items[i].Spec.PoolRef; ref != nil && ref.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&machineList.Items[i])})
+		}
+	}
+	return requests
+}
+
+// pooledServerSelectors returns the selectors of the Servers of the pools. Pools reserve their Servers, hence
+// IroncoreMetalMachines without pool never claim them.
+func pooledServerSelectors(pools []infrav1alpha1.IroncoreMetalServerPool) ([]labels.Selector, error) {
+	selectors := make([]labels.Selector, 0, len(pools))
+	for i := range pools {
+		selector, err := metav1.LabelSelectorAsSelector(&pools[i].Spec.ServerSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ServerSelector of IroncoreMetalServerPool %s: %w", pools[i].Name, err)
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// serverPoolAllows reports whether the cluster may use the pool. Pools which neither list namespaces nor clusters
+// may be used by all clusters.
+func serverPoolAllows(pool *infrav1alpha1.IroncoreMetalServerPool, namespace, cluster string) bool {
+	if len(pool.Spec.AllowedNamespaces) == 0 && len(pool.Spec.AllowedClusters) == 0 {
+		return true
+	}
+	return slices.Contains(pool.Spec.AllowedNamespaces, namespace) ||
+		slices.Contains(pool.Spec.AllowedClusters, infrav1alpha1.ClusterReference{Namespace: namespace, Name: cluster})
+}
+
+// serverPoolFailureReason returns the reason of the ServerAvailable condition for an error getting the
+// IroncoreMetalServerPool, or an empty string if the error is transient.
+func serverPoolFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errServerPoolNotFound):
+		return infrav1alpha1.ServerPoolNotFoundReason
+	case errors.Is(err, errServerPoolNotAllowed):
+		return infrav1alpha1.ServerPoolNotAllowedReason
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+)
+
+// IroncoreMetalServerPoolReconciler reconciles a IroncoreMetalServerPool object
+type IroncoreMetalServerPoolReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalserverpools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalserverpools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=servers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=serverclaims,verbs=get;list;watch
+
+// Reconcile counts the total, available and claimed Servers of an IroncoreMetalServerPool.
+func (r *IroncoreMetalServerPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	pool := &infrav1alpha1.IroncoreMetalServerPool{}
+	if err := r.Get(ctx, req.NamespacedName, pool); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !pool.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&pool.Spec.ServerSelector)
+	if err != nil {
+		logger.Error(err, "IroncoreMetalServerPool has an invalid ServerSelector")
+		return ctrl.Result{}, nil
+	}
+	serverList := &metalv1alpha1.ServerList{}
+	if err := r.List(ctx, serverList); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list Servers: %w", err)
+	}
+	claimList := &metalv1alpha1.ServerClaimList{}
+	if err := r.List(ctx, claimList); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list ServerClaims: %w", err)
+	}
+	availability := placement.Count(serverList.Items, claimedServers(claimList.Items), placement.Request{Selector: selector})
+
+	status := infrav1alpha1.IroncoreMetalServerPoolStatus{
+		Total:     int32(availability.Matching),
+		Available: int32(availability.Available),
+		Claimed:   int32(availability.Claimed),
+	}
+	if pool.Status == status {
+		return ctrl.Result{}, nil
+	}
+
+	base := pool.DeepCopy()
+	pool.Status = status
+	if err := r.Status().Patch(ctx, pool, client.MergeFrom(base)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to patch status of IroncoreMetalServerPool: %w", err)
+	}
+	logger.V(3).Info("Updated Servers of IroncoreMetalServerPool", "total", status.Total, "available", status.Available, "claimed", status.Claimed)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IroncoreMetalServerPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1alpha1.IroncoreMetalServerPool{}).
+		Watches(
+			&metalv1alpha1.Server{},
+			handler.EnqueueRequestsFromMapFunc(r.allIroncoreMetalServerPools),
+		).
+		Watches(
+			&metalv1alpha1.ServerClaim{},
+			handler.EnqueueRequestsFromMapFunc(r.allIroncoreMetalServerPools),
+		).
+		Complete(r)
+}
+
+// allIroncoreMetalServerPools enqueues all IroncoreMetalServerPools, as the Server or ServerClaim may change the
+// numbers of any of them.
+func (r *IroncoreMetalServerPoolReconciler) allIroncoreMetalServerPools(ctx context.Context, _ client.Object) []reconcile.Request {
+	poolList := &infrav1alpha1.IroncoreMetalServerPoolList{}
+	if err := r.List(ctx, poolList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list IroncoreMetalServerPools")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(poolList.Items))
+	for i := range poolList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&poolList.Items[i])})
+	}
+	return requests
+}
@@ -0,0 +1,348 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// CloudConfigVersion is the spec version of ignitions converted from cloud-configs.
+	CloudConfigVersion = "3.3.0"
+
+	cloudConfigDir = "/etc/metal/cloud-config"
+)
+
+var (
+	// cloudConfigModules are the cloud-config modules FromCloudConfig converts. Configs using other modules are
+	// rejected instead of silently dropping parts of them.
+	cloudConfigModules = sets.New("write_files", "bootcmd", "runcmd", "users")
+
+	jinjaExpression = regexp.MustCompile(`{{-?\s*(ds|v1)\.`)
+	// hostnameExpression matches the jinja expressions bootstrap providers use for the hostname, e.g. the
+	// {{ ds.meta_data.local_hostname }} of kubeadm.
+	hostnameExpression = regexp.MustCompile(`{{-?\s*(ds\.meta_data|v1)\.(local_hostname|hostname)\s*-?}}`)
+)
+
+// InstanceData are the values of the cloud-init instance data which RenderJinja substitutes.
+type InstanceData struct {
+	// Hostname is the hostname of the server.
+	Hostname string
+}
+
+// RenderJinja replaces the jinja expressions referencing the instance data in the cloud-config. Expressions
+// referencing other instance data are kept, so FromCloudConfig rejects them.
+func RenderJinja(data []byte, instanceData InstanceData) []byte {
+	return hostnameExpression.ReplaceAllLiteral(data, []byte(instanceData.Hostname))
+}
+
+type cloudConfig struct {
+	WriteFiles []cloudConfigFile `json:"write_files"`
+	BootCmd    []json.RawMessage `json:"bootcmd"`
+	RunCmd     []json.RawMessage `json:"runcmd"`
+	Users      []json.RawMessage `json:"users"`
+}
+
+type cloudConfigFile struct {
+	Path        string          `json:"path"`
+	Content     string          `json:"content"`
+	Encoding    string          `json:"encoding"`
+	Owner       string          `json:"owner"`
+	Permissions json.RawMessage `json:"permissions"`
+	Append      bool            `json:"append"`
+}
+
+type cloudConfigUser struct {
+	Name              string          `json:"name"`
+	Passwd            string          `json:"passwd"`
+	Shell             string          `json:"shell"`
+	Groups            json.RawMessage `json:"groups"`
+	Sudo              json.RawMessage `json:"sudo"`
+	SSHAuthorizedKeys []string        `json:"ssh_authorized_keys"`
+}
+
+// FromCloudConfig converts the cloud-config into an Ignition config. It supports the write_files, bootcmd,
+// runcmd and users modules, which are used by the kubeadm, k3s and RKE2 bootstrap providers.
+// bootcmd and runcmd are written to scripts which are run by oneshot systemd units, runcmd only on first boot.
+// Jinja expressions have to be rendered with RenderJinja before.
+func FromCloudConfig(data []byte) (Config, error) {
+	if jinjaExpression.Match(data) {
+		return nil, fmt.Errorf("cloud-config uses jinja expressions of cloud-init, which can not be rendered")
+	}
+
+	var modules map[string]json.RawMessage
+	if err := yaml.Unmarshal(data, &modules); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-config: %w", err)
+	}
+	if unsupported := sets.KeySet(modules).Difference(cloudConfigModules); unsupported.Len() > 0 {
+		return nil, fmt.Errorf("cloud-config uses unsupported modules %v", sets.List(unsupported))
+	}
+	var cc cloudConfig
+	if err := yaml.Unmarshal(data, &cc); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-config: %w", err)
+	}
+
+	config := Config{"ignition": map[string]any{"version": CloudConfigVersion}}
+	if err := config.addCloudConfigFiles(cc.WriteFiles); err != nil {
+		return nil, err
+	}
+	if err := config.addCloudConfigUsers(cc.Users); err != nil {
+		return nil, err
+	}
+	if err := config.addCloudConfigCommands("bootcmd", cc.BootCmd); err != nil {
+		return nil, err
+	}
+	if err := config.addCloudConfigCommands("runcmd", cc.RunCmd); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c Config) addCloudConfigFiles(writeFiles []cloudConfigFile) error {
+	// cloud-init applies the entries in order, so entries appending to a file written before are merged into it.
+	files := map[string]*File{}
+	var order []string
+	for i, wf := range writeFiles {
+		if !path.IsAbs(wf.Path) {
+			return fmt.Errorf("write_files[%d]: path %q must be absolute", i, wf.Path)
+		}
+		filePath := path.Clean(wf.Path)
+		contents, err := decodeCloudConfigContent(wf.Content, wf.Encoding)
+		if err != nil {
+			return fmt.Errorf("write_files[%d]: %w", i, err)
+		}
+		mode, err := parsePermissions(wf.Permissions)
+		if err != nil {
+			return fmt.Errorf("write_files[%d]: %w", i, err)
+		}
+		user, group, _ := strings.Cut(wf.Owner, ":")
+
+		if file, ok := files[filePath]; ok && wf.Append {
+			file.Contents = append(file.Contents, contents...)
+			continue
+		}
+		if _, ok := files[filePath]; !ok {
+			order = append(order, filePath)
+		}
+		files[filePath] = &File{Path: filePath, Mode: mode, Contents: contents, User: user, Group: group, Append: wf.Append}
+	}
+	for _, filePath := range order {
+		c.AddFile(*files[filePath])
+	}
+	return nil
+}
+
+func (c Config) addCloudConfigUsers(users []json.RawMessage) error {
+	for i, raw := range users {
+		var name string
+		if err := json.Unmarshal(raw, &name); err == nil {
+			// The default user is defined by the image, not by the config.
+			if name != "default" {
+				c.AddUser(User{Name: name})
+			}
+			continue
+		}
+
+		var u cloudConfigUser
+		if err := json.Unmarshal(raw, &u); err != nil {
+			return fmt.Errorf("users[%d]: %w", i, err)
+		}
+		if u.Name == "" {
+			return fmt.Errorf("users[%d]: name is required", i)
+		}
+		groups, err := stringOrList(u.Groups, ",")
+		if err != nil {
+			return fmt.Errorf("users[%d]: groups: %w", i, err)
+		}
+		sudo, err := sudoRules(u.Sudo)
+		if err != nil {
+			return fmt.Errorf("users[%d]: sudo: %w", i, err)
+		}
+
+		c.AddUser(User{
+			Name:              u.Name,
+			PasswordHash:      u.Passwd,
+			Shell:             u.Shell,
+			Groups:            groups,
+			SSHAuthorizedKeys: u.SSHAuthorizedKeys,
+		})
+		if len(sudo) > 0 {
+			var rules strings.Builder
+			for _, rule := range sudo {
+				fmt.Fprintf(&rules, "%s %s\n", u.Name, rule)
+			}
+			c.AddFile(File{Path: "/etc/sudoers.d/" + u.Name, Mode: 0o440, Contents: []byte(rules.String())})
+		}
+	}
+	return nil
+}
+
+// addCloudConfigCommands writes the commands of the bootcmd or runcmd module to a script and adds a unit running it.
+func (c Config) addCloudConfigCommands(module string, commands []json.RawMessage) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	script := []string{"#!/bin/sh"}
+	for i, raw := range commands {
+		command, err := shellCommand(raw)
+		if err != nil {
+			return fmt.Errorf("%s[%d]: %w", module, i, err)
+		}
+		script = append(script, command)
+	}
+	scriptPath := path.Join(cloudConfigDir, module+".sh")
+	c.AddFile(File{Path: scriptPath, Mode: 0o755, Contents: []byte(strings.Join(script, "\n") + "\n")})
+
+	unit := []string{"[Unit]", fmt.Sprintf("Description=cloud-config %s", module)}
+	execStart := []string{"ExecStart=/bin/sh " + scriptPath}
+	if module == "runcmd" {
+		// runcmd runs once per instance, like with cloud-init.
+		donePath := path.Join(cloudConfigDir, module+".done")
+		unit = append(unit,
+			"Wants=network-online.target",
+			"After=network-online.target cloud-config-bootcmd.service",
+			"ConditionPathExists=!"+donePath,
+		)
+		execStart = append(execStart, "ExecStartPost=/bin/touch "+donePath)
+	} else {
+		unit = append(unit, "DefaultDependencies=no", "After=local-fs.target", "Before=network-pre.target")
+	}
+	unit = append(unit, "", "[Service]", "Type=oneshot", "RemainAfterExit=yes")
+	unit = append(unit, execStart...)
+	unit = append(unit, "", "[Install]", "WantedBy=multi-user.target")
+
+	c.AddUnit(Unit{
+		Name:     fmt.Sprintf("cloud-config-%s.service", module),
+		Enabled:  true,
+		Contents: strings.Join(unit, "\n") + "\n",
+	})
+	return nil
+}
+
+func decodeCloudConfigContent(content, encoding string) ([]byte, error) {
+	encoding = strings.ToLower(encoding)
+	data := []byte(content)
+	if strings.Contains(encoding, "b64") || strings.Contains(encoding, "base64") {
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 content: %w", err)
+		}
+		data = decoded
+	}
+
+	switch encoding {
+	case "", "text/plain", "b64", "base64":
+		return data, nil
+	case "gz", "gzip", "gz+b64", "gz+base64", "gzip+b64", "gzip+base64":
+		return gunzip(data)
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content: %w", err)
+	}
+	return contents, nil
+}
+
+// parsePermissions parses the octal permissions of a write_files entry, which are either a string like "0644"
+// or a number which YAML already parsed as octal.
+func parsePermissions(raw json.RawMessage) (int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return DefaultFileMode, nil
+	}
+	var permissions string
+	if err := json.Unmarshal(raw, &permissions); err == nil {
+		mode, err := strconv.ParseUint(permissions, 8, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid permissions %q", permissions)
+		}
+		return int(mode), nil
+	}
+	var mode int
+	if err := json.Unmarshal(raw, &mode); err != nil || mode < 0 {
+		return 0, fmt.Errorf("invalid permissions %s", raw)
+	}
+	return mode, nil
+}
+
+// shellCommand returns a command of bootcmd or runcmd as shell command line. Commands are either a string,
+// which is run by the shell as is, or a list of arguments, which are quoted.
+func shellCommand(raw json.RawMessage) (string, error) {
+	var command string
+	if err := json.Unmarshal(raw, &command); err == nil {
+		return command, nil
+	}
+	var args []string
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("command must be a string or a list of strings")
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// stringOrList returns a list of strings given either as list or as string separated by sep.
+func stringOrList(raw json.RawMessage, sep string) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("must be a string or a list of strings")
+	}
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+// sudoRules returns the sudo rules of a user, which are a single rule, a list of rules or false.
+func sudoRules(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var disabled bool
+	if err := json.Unmarshal(raw, &disabled); err == nil {
+		return nil, nil
+	}
+	var rule string
+	if err := json.Unmarshal(raw, &rule); err == nil {
+		return []string{rule}, nil
+	}
+	var rules []string
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("must be a string, a list of strings or false")
+	}
+	return rules, nil
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FromCloudConfig", func() {
+	It("should convert a kubeadm cloud-config", func() {
+		config, err := FromCloudConfig([]byte(`## template: jinja
+#cloud-config
+
+write_files:
+- path: /etc/kubernetes/pki/ca.crt
+  owner: root:root
+  permissions: '0640'
+  content: |
+    CA
+- path: /etc/kubernetes/pki/ca.crt
+  append: true
+  content: "MORE\n"
+- path: /run/kubeadm/kubeadm.yaml
+  encoding: b64
+  content: a2luZDogSW5pdENvbmZpZ3VyYXRpb24=
+runcmd:
+- kubeadm init --config /run/kubeadm/kubeadm.yaml
+- [sh, -c, "echo 'done'"]
+users:
+- default
+- name: capi
+  sudo: ALL=(ALL) NOPASSWD:ALL
+  groups: wheel, docker
+  ssh_authorized_keys:
+  - ssh-ed25519 AAAA capi
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Validate()).To(Succeed())
+
+		data, err := config.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"ignition": {"version": "3.3.0"},
+			"storage": {"files": [
+				{
+					"path": "/etc/kubernetes/pki/ca.crt",
+					"mode": 416,
+					"overwrite": true,
+					"user": {"name": "root"},
+					"group": {"name": "root"},
+					"contents": {"source": "data:;base64,Q0EKTU9SRQo="}
+				},
+				{
+					"path": "/run/kubeadm/kubeadm.yaml",
+					"mode": 420,
+					"overwrite": true,
+					"contents": {"source": "data:;base64,a2luZDogSW5pdENvbmZpZ3VyYXRpb24="}
+				},
+				{
+					"path": "/etc/sudoers.d/capi",
+					"mode": 288,
+					"overwrite": true,
+					"contents": {"source": "data:;base64,Y2FwaSBBTEw9KEFMTCkgTk9QQVNTV0Q6QUxMCg=="}
+				},
+				{
+					"path": "/etc/metal/cloud-config/runcmd.sh",
+					"mode": 493,
+					"overwrite": true,
+					"contents": {"source": "data:;base64,IyEvYmluL3NoCmt1YmVhZG0gaW5pdCAtLWNvbmZpZyAvcnVuL2t1YmVhZG0va3ViZWFkbS55YW1sCidzaCcgJy1jJyAnZWNobyAnXCcnZG9uZSdcJycnCg=="}
+				}
+			]},
+			"passwd": {"users": [{
+				"name": "capi",
+				"groups": ["wheel", "docker"],
+				"sshAuthorizedKeys": ["ssh-ed25519 AAAA capi"]
+			}]},
+			"systemd": {"units": [{
+				"name": "cloud-config-runcmd.service",
+				"enabled": true,
+				"contents": "[Unit]\nDescription=cloud-config runcmd\nWants=network-online.target\nAfter=network-online.target cloud-config-bootcmd.service\nConditionPathExists=!/etc/metal/cloud-config/runcmd.done\n\n[Service]\nType=oneshot\nRemainAfterExit=yes\nExecStart=/bin/sh /etc/metal/cloud-config/runcmd.sh\nExecStartPost=/bin/touch /etc/metal/cloud-config/runcmd.done\n\n[Install]\nWantedBy=multi-user.target\n"
+			}]}
+		}`))
+	})
+
+	It("should decode gzip compressed files", func() {
+		config, err := FromCloudConfig([]byte(`#cloud-config
+write_files:
+- path: /etc/foo
+  encoding: gz+b64
+  content: H4sIAAAAAAAA/0vLz+cCAKhlMn4EAAAA
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.files()).To(ConsistOf(HaveKeyWithValue("contents", HaveKeyWithValue("source", DataURL([]byte("foo\n"))))))
+	})
+
+	It("should add a bootcmd unit", func() {
+		config, err := FromCloudConfig([]byte("#cloud-config\nbootcmd:\n- echo boot\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Validate()).To(Succeed())
+		Expect(config["systemd"]).To(HaveKeyWithValue("units", ConsistOf(HaveKeyWithValue("name", "cloud-config-bootcmd.service"))))
+	})
+
+	DescribeTable("should reject cloud-configs which can not be converted",
+		func(cloudConfig, message string) {
+			_, err := FromCloudConfig([]byte(cloudConfig))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("unsupported module", "#cloud-config\nntp:\n  enabled: true\n", "unsupported modules [ntp]"),
+		Entry("jinja expression", "## template: jinja\n#cloud-config\nruncmd:\n- echo {{ ds.meta_data.instance_id }}\n", "jinja"),
+		Entry("relative path", "#cloud-config\nwrite_files:\n- path: etc/foo\n", "must be absolute"),
+		Entry("unsupported encoding", "#cloud-config\nwrite_files:\n- path: /etc/foo\n  encoding: zstd\n", "unsupported encoding"),
+		Entry("invalid permissions", "#cloud-config\nwrite_files:\n- path: /etc/foo\n  permissions: '0999'\n", "invalid permissions"),
+		Entry("invalid command", "#cloud-config\nruncmd:\n- {foo: bar}\n", "runcmd[0]"),
+		Entry("invalid YAML", "#cloud-config\nwrite_files: [\n", "failed to parse"),
+	)
+})
+
+var _ = Describe("RenderJinja", func() {
+	It("should render the hostname of kubeadm cloud-configs", func() {
+		data := RenderJinja([]byte(`## template: jinja
+#cloud-config
+write_files:
+- path: /run/kubeadm/kubeadm.yaml
+  content: |
+    nodeRegistration:
+      name: '{{ ds.meta_data.local_hostname }}'
+runcmd:
+- hostnamectl set-hostname {{v1.local_hostname}}
+- echo {{ ds.meta_data.instance_id }}
+`), InstanceData{Hostname: "machine-0"})
+
+		Expect(string(data)).To(ContainSubstring("name: 'machine-0'"))
+		Expect(string(data)).To(ContainSubstring("set-hostname machine-0\n"))
+		Expect(string(data)).To(ContainSubstring("{{ ds.meta_data.instance_id }}"))
+	})
+})
+
+var _ = Describe("AddUser", func() {
+	It("should merge users with the same name", func() {
+		config := Config{"ignition": map[string]any{"version": "3.4.0"}}
+		config.AddUser(User{Name: "core", SSHAuthorizedKeys: []string{"key-1"}})
+		config.AddUser(User{Name: "core", Shell: "/bin/bash", SSHAuthorizedKeys: []string{"key-1", "key-2"}})
+
+		data, err := config.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"ignition": {"version": "3.4.0"},
+			"passwd": {"users": [{"name": "core", "shell": "/bin/bash", "sshAuthorizedKeys": ["key-1", "key-2"]}]}
+		}`))
+	})
+})
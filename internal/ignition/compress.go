@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+)
+
+// CompressFiles gzip compresses the inline data URL contents of all files larger than threshold bytes.
+// Files which are compressed already, carry a verification hash or do not shrink are left untouched.
+func (c Config) CompressFiles(threshold int) error {
+	for _, file := range c.files() {
+		contents, _ := file["contents"].(map[string]any)
+		source, _ := contents["source"].(string)
+		if !strings.HasPrefix(source, "data:") {
+			continue
+		}
+		if compression, _ := contents["compression"].(string); compression != "" {
+			continue
+		}
+		if _, ok := contents["verification"]; ok {
+			continue
+		}
+
+		data, err := DecodeDataURL(source)
+		if err != nil {
+			return fmt.Errorf("failed to decode contents of file %v: %w", file["path"], err)
+		}
+		if len(data) < threshold {
+			continue
+		}
+
+		compressed, err := gzipData(data)
+		if err != nil {
+			return fmt.Errorf("failed to compress contents of file %v: %w", file["path"], err)
+		}
+		if len(compressed) >= len(data) {
+			continue
+		}
+		contents["source"] = DataURL(compressed)
+		contents["compression"] = "gzip"
+	}
+	return nil
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
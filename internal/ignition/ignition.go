@@ -0,0 +1,302 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ignition provides helpers to amend the Ignition configs rendered by bootstrap providers.
+//
+// Bootstrap providers render either Ignition spec 2.x (e.g. CABPK via the Container Linux Config transpiler)
+// or spec 3.x configs. Config keeps the rendered document as generic JSON, so that fields which are not
+// managed by this package survive a round trip unchanged.
+package ignition
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultFileMode is the mode of files added to a Config when no mode is specified (0644).
+	DefaultFileMode = 0o644
+)
+
+// Config is a parsed Ignition config.
+type Config map[string]any
+
+// File is a regular file which is written to the root filesystem of the server.
+type File struct {
+	// Path is the absolute path of the file.
+	Path string
+	// Mode is the file mode. DefaultFileMode is used if it is zero.
+	Mode int
+	// Contents are the raw contents of the file.
+	Contents []byte
+	// User and Group are the names of the owner of the file. The owner is left to Ignition if empty.
+	User, Group string
+	// Append appends Contents to the file instead of replacing it.
+	Append bool
+}
+
+// Unit is a systemd unit.
+type Unit struct {
+	// Name is the name of the unit including its type suffix, e.g. kubelet.service.
+	Name string
+	// Enabled enables the unit.
+	Enabled bool
+	// Contents are the contents of the unit file.
+	Contents string
+}
+
+// Dropin is a systemd drop-in of a unit.
+type Dropin struct {
+	// Name is the name of the drop-in file, which must end with .conf.
+	Name string
+	// Contents are the contents of the drop-in file.
+	Contents string
+}
+
+// User is a user account on the server.
+type User struct {
+	// Name is the name of the user.
+	Name string
+	// PasswordHash is the hashed password of the user.
+	PasswordHash string
+	// Shell is the login shell of the user.
+	Shell string
+	// Groups are the supplementary groups of the user.
+	Groups []string
+	// SSHAuthorizedKeys are the public keys which may log in as the user.
+	SSHAuthorizedKeys []string
+}
+
+// Parse parses the given Ignition config.
+func Parse(data []byte) (Config, error) {
+	config := Config{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse ignition config: %w", err)
+	}
+	return config, nil
+}
+
+// Marshal returns the JSON representation of the config.
+func (c Config) Marshal() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// Version returns the spec version of the config, e.g. "3.4.0".
+func (c Config) Version() string {
+	ignition, _ := c["ignition"].(map[string]any)
+	version, _ := ignition["version"].(string)
+	return version
+}
+
+// IsV2 reports whether the config uses an Ignition spec 2.x layout.
+func (c Config) IsV2() bool {
+	return strings.HasPrefix(c.Version(), "2.")
+}
+
+// AddFile adds the file to the storage section of the config. An existing file with the same path is replaced.
+func (c Config) AddFile(file File) {
+	mode := file.Mode
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+
+	entry := map[string]any{
+		"path": file.Path,
+		"mode": mode,
+	}
+	contents := map[string]any{
+		"source": DataURL(file.Contents),
+	}
+	switch {
+	case c.IsV2():
+		entry["filesystem"] = "root"
+		entry["contents"] = contents
+		if file.Append {
+			entry["append"] = true
+		}
+	case file.Append:
+		entry["append"] = []any{contents}
+	default:
+		entry["contents"] = contents
+		entry["overwrite"] = true
+	}
+	if file.User != "" {
+		entry["user"] = map[string]any{"name": file.User}
+	}
+	if file.Group != "" {
+		entry["group"] = map[string]any{"name": file.Group}
+	}
+
+	storage := c.section("storage")
+	storage["files"] = replaceOrAppend(storage["files"], "path", file.Path, entry)
+}
+
+// AddUnit adds the unit to the systemd section of the config. An existing unit with the same name is replaced.
+func (c Config) AddUnit(unit Unit) {
+	entry := map[string]any{
+		"name":     unit.Name,
+		"contents": unit.Contents,
+	}
+	if unit.Enabled {
+		entry["enabled"] = true
+	}
+
+	systemd := c.section("systemd")
+	systemd["units"] = replaceOrAppend(systemd["units"], "name", unit.Name, entry)
+}
+
+// AddDropin adds the drop-in to the unit with the given name. An existing drop-in with the same name is replaced.
+// The unit itself is left untouched, it is added without contents if the config does not define it.
+func (c Config) AddDropin(unitName string, dropin Dropin) {
+	systemd := c.section("systemd")
+	var unit map[string]any
+	unit, systemd["units"] = findOrAppend(systemd["units"], "name", unitName)
+	unit["dropins"] = replaceOrAppend(unit["dropins"], "name", dropin.Name, map[string]any{
+		"name":     dropin.Name,
+		"contents": dropin.Contents,
+	})
+}
+
+// AddUser adds the user to the passwd section of the config. The groups and keys of an existing user with the
+// same name are merged with the ones of user, its password hash and shell are replaced if set.
+func (c Config) AddUser(user User) {
+	passwd := c.section("passwd")
+	var entry map[string]any
+	entry, passwd["users"] = findOrAppend(passwd["users"], "name", user.Name)
+
+	if user.PasswordHash != "" {
+		entry["passwordHash"] = user.PasswordHash
+	}
+	if user.Shell != "" {
+		entry["shell"] = user.Shell
+	}
+	if groups := mergeStrings(entry["groups"], user.Groups); len(groups) > 0 {
+		entry["groups"] = groups
+	}
+	if keys := mergeStrings(entry["sshAuthorizedKeys"], user.SSHAuthorizedKeys); len(keys) > 0 {
+		entry["sshAuthorizedKeys"] = keys
+	}
+}
+
+// AddUserExpiry adds units which lock the user at expiresAt. The user is also locked on boot once it expired,
+// in case the server was down at that time.
+func (c Config) AddUserExpiry(name string, expiresAt time.Time) {
+	unitName := fmt.Sprintf("expire-user-%s", name)
+	c.AddUnit(Unit{
+		Name: unitName + ".service",
+		Contents: fmt.Sprintf(`[Unit]
+Description=Lock expired user %[1]s
+
+[Service]
+Type=oneshot
+ExecCondition=/bin/sh -c 'test "$(date +%%s)" -ge %[2]d'
+ExecStart=/usr/sbin/usermod --lock --expiredate 1 %[1]s
+`, name, expiresAt.Unix()),
+	})
+	c.AddUnit(Unit{
+		Name:    unitName + ".timer",
+		Enabled: true,
+		Contents: fmt.Sprintf(`[Unit]
+Description=Lock user %[1]s when it expires
+
+[Timer]
+OnBootSec=0
+OnCalendar=%[2]s
+
+[Install]
+WantedBy=timers.target
+`, name, expiresAt.UTC().Format("2006-01-02 15:04:05 UTC")),
+	})
+}
+
+// DataURL returns a base64 encoded RFC 2397 data URL of the given contents.
+func DataURL(contents []byte) string {
+	return "data:;base64," + base64.StdEncoding.EncodeToString(contents)
+}
+
+// DecodeDataURL returns the contents of the given RFC 2397 data URL.
+func DecodeDataURL(source string) ([]byte, error) {
+	rest, ok := strings.CutPrefix(source, "data:")
+	if !ok {
+		return nil, fmt.Errorf("%q is not a data URL", source)
+	}
+	header, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, fmt.Errorf("data URL is missing a comma")
+	}
+	if strings.HasSuffix(header, ";base64") {
+		contents, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 data URL: %w", err)
+		}
+		return contents, nil
+	}
+	contents, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode data URL: %w", err)
+	}
+	return []byte(contents), nil
+}
+
+// files returns the file entries of the storage section.
+func (c Config) files() []map[string]any {
+	storage, _ := c["storage"].(map[string]any)
+	items, _ := storage["files"].([]any)
+	files := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if file, ok := item.(map[string]any); ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// section returns the top level object with the given name, creating it if needed.
+func (c Config) section(name string) map[string]any {
+	if s, ok := c[name].(map[string]any); ok {
+		return s
+	}
+	s := map[string]any{}
+	c[name] = s
+	return s
+}
+
+// replaceOrAppend replaces the entry in list whose key equals value, or appends entry if there is none.
+func replaceOrAppend(list any, key, value string, entry map[string]any) []any {
+	items, _ := list.([]any)
+	for i, item := range items {
+		if m, ok := item.(map[string]any); ok && m[key] == value {
+			items[i] = entry
+			return items
+		}
+	}
+	return append(items, entry)
+}
+
+// findOrAppend returns the entry in list whose key equals value, appending a new entry if there is none.
+func findOrAppend(list any, key, value string) (map[string]any, []any) {
+	items, _ := list.([]any)
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok && m[key] == value {
+			return m, items
+		}
+	}
+	entry := map[string]any{key: value}
+	return entry, append(items, entry)
+}
+
+// mergeStrings appends the values which are not yet contained in list.
+func mergeStrings(list any, values []string) []any {
+	items, _ := list.([]any)
+	for _, value := range values {
+		if !slices.Contains(items, any(value)) {
+			items = append(items, value)
+		}
+	}
+	return items
+}
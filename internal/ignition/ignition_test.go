@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config", func() {
+	It("should add files to a spec 3 config", func() {
+		config, err := Parse([]byte(`{"ignition":{"version":"3.4.0"},"systemd":{"units":[{"name":"foo.service"}]}}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		config.AddFile(File{Path: "/etc/metal/metadata.json", Contents: []byte("{}")})
+
+		data, err := config.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"ignition": {"version": "3.4.0"},
+			"systemd": {"units": [{"name": "foo.service"}]},
+			"storage": {"files": [{
+				"path": "/etc/metal/metadata.json",
+				"mode": 420,
+				"overwrite": true,
+				"contents": {"source": "data:;base64,e30="}
+			}]}
+		}`))
+	})
+
+	It("should replace existing files in a spec 2 config", func() {
+		config, err := Parse([]byte(`{"ignition":{"version":"2.3.0"},"storage":{"files":[{"filesystem":"root","path":"/etc/foo","contents":{"source":"data:,old"}}]}}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		config.AddFile(File{Path: "/etc/foo", Mode: 0o600, Contents: []byte("new")})
+
+		data, err := config.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"ignition": {"version": "2.3.0"},
+			"storage": {"files": [{
+				"filesystem": "root",
+				"path": "/etc/foo",
+				"mode": 384,
+				"contents": {"source": "data:;base64,bmV3"}
+			}]}
+		}`))
+	})
+
+	It("should add units locking an expiring user", func() {
+		config := Config{"ignition": map[string]any{"version": "3.4.0"}}
+		config.AddUserExpiry("breakglass", time.Date(2024, 10, 1, 12, 30, 0, 0, time.UTC))
+		Expect(config.Validate()).To(Succeed())
+
+		units := config["systemd"].(map[string]any)["units"]
+		Expect(units).To(ConsistOf(
+			SatisfyAll(
+				HaveKeyWithValue("name", "expire-user-breakglass.service"),
+				HaveKeyWithValue("contents", ContainSubstring("-ge 1727785800")),
+				HaveKeyWithValue("contents", ContainSubstring("usermod --lock --expiredate 1 breakglass")),
+			),
+			SatisfyAll(
+				HaveKeyWithValue("name", "expire-user-breakglass.timer"),
+				HaveKeyWithValue("enabled", true),
+				HaveKeyWithValue("contents", ContainSubstring("OnCalendar=2024-10-01 12:30:00 UTC")),
+			),
+		))
+	})
+
+	It("should fail to parse invalid JSON", func() {
+		_, err := Parse([]byte(`{"ignition":`))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("CompressFiles", func() {
+	It("should only compress large files", func() {
+		config, err := Parse([]byte(`{"ignition":{"version":"3.4.0"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		large := strings.Repeat("kubeadm ", 1024)
+		config.AddFile(File{Path: "/etc/large", Contents: []byte(large)})
+		config.AddFile(File{Path: "/etc/small", Contents: []byte("small")})
+
+		Expect(config.CompressFiles(1024)).To(Succeed())
+
+		files := config.files()
+		Expect(files).To(HaveLen(2))
+		largeContents := files[0]["contents"].(map[string]any)
+		Expect(largeContents).To(HaveKeyWithValue("compression", "gzip"))
+		Expect(len(largeContents["source"].(string))).To(BeNumerically("<", len(large)))
+		Expect(files[1]["contents"]).NotTo(HaveKey("compression"))
+	})
+})
+
+var _ = Describe("Split", func() {
+	It("should not split configs which fit", func() {
+		config, err := Parse([]byte(`{"ignition":{"version":"3.4.0"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		config.AddFile(File{Path: "/etc/foo", Contents: []byte("foo")})
+
+		parts, err := config.Split(4096)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parts).To(BeEmpty())
+	})
+
+	It("should move the largest files into parts", func() {
+		config, err := Parse([]byte(`{"ignition":{"version":"2.3.0"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		config.AddFile(File{Path: "/etc/small", Contents: []byte("small")})
+		config.AddFile(File{Path: "/etc/large-1", Contents: []byte(strings.Repeat("a", 2048))})
+		config.AddFile(File{Path: "/etc/large-2", Contents: []byte(strings.Repeat("b", 1024))})
+
+		parts, err := config.Split(4096)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parts).To(HaveLen(1))
+		Expect(parts[0].files()).To(ConsistOf(HaveKeyWithValue("path", "/etc/large-1")))
+		Expect(config.files()).To(ConsistOf(
+			HaveKeyWithValue("path", "/etc/small"),
+			HaveKeyWithValue("path", "/etc/large-2"),
+		))
+
+		for i, part := range parts {
+			Expect(part.Version()).To(Equal("2.3.0"))
+			data, err := part.Marshal()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(data)).To(BeNumerically("<=", 4096))
+			config.AddMergeSource(fmt.Sprintf("http://parts/%d", i), data)
+		}
+		Expect(config["ignition"]).To(HaveKeyWithValue("config", HaveKeyWithValue("append", HaveLen(1))))
+	})
+
+	It("should trust the certificate authority once", func() {
+		config, err := Parse([]byte(`{"ignition":{"version":"3.4.0"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		config.AddCertificateAuthority([]byte("ca"))
+		config.AddCertificateAuthority([]byte("ca"))
+
+		Expect(config["ignition"]).To(HaveKeyWithValue("security", HaveKeyWithValue("tls",
+			HaveKeyWithValue("certificateAuthorities", ConsistOf(HaveKeyWithValue("source", DataURL([]byte("ca"))))))))
+		Expect(config.Validate()).To(Succeed())
+	})
+
+	It("should fail if a single file does not fit", func() {
+		config, err := Parse([]byte(`{"ignition":{"version":"3.4.0"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		config.AddFile(File{Path: "/etc/huge", Contents: []byte(strings.Repeat("a", 8192))})
+
+		_, err = config.Split(4096)
+		Expect(err).To(MatchError(ErrTooLarge))
+	})
+})
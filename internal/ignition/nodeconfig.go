@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// ContainerdHostsDir is the directory containerd reads the hosts.toml files of registries from.
+	ContainerdHostsDir = "/etc/containerd/certs.d"
+	// TrustedCABundlePath is the path the trusted CA bundle is written to before it is installed as trust anchor.
+	TrustedCABundlePath = "/etc/metal/ca-certificates/trusted-ca-bundle.crt"
+
+	// defaultRegistry is the containerd hosts directory which applies to all registries without own directory.
+	defaultRegistry = "_default"
+
+	updateCATrustScriptPath = "/etc/metal/ca-certificates/update-ca-trust.sh"
+	updateCATrustScript     = `#!/bin/sh
+# Installs the trusted CA bundle as trust anchor of the distribution and updates the trust store.
+set -e
+bundle=` + TrustedCABundlePath + `
+if command -v update-ca-trust >/dev/null 2>&1; then
+	cp "$bundle" /etc/pki/ca-trust/source/anchors/metal-trusted-ca-bundle.crt
+	update-ca-trust extract
+elif [ -w /usr/local/share/ca-certificates ]; then
+	cp "$bundle" /usr/local/share/ca-certificates/metal-trusted-ca-bundle.crt
+	update-ca-certificates
+else
+	cp "$bundle" /etc/ssl/certs/metal-trusted-ca-bundle.pem
+	update-ca-certificates
+fi
+`
+	updateCATrustUnit = `[Unit]
+Description=Install trusted CA bundle
+DefaultDependencies=no
+After=local-fs.target
+Before=containerd.service kubelet.service
+ConditionPathExists=` + TrustedCABundlePath + `
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/bin/sh ` + updateCATrustScriptPath + `
+
+[Install]
+WantedBy=multi-user.target
+`
+)
+
+// ProxyUnits are the units which use the HTTP proxy configured by AddProxy.
+var ProxyUnits = []string{"containerd.service", "kubelet.service"}
+
+// Proxy is the HTTP proxy configuration of a server.
+type Proxy struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    []string
+}
+
+// AddRegistryMirror adds the containerd hosts.toml file which makes containerd pull images of registry through
+// the given mirror endpoints, falling back to the registry itself.
+func (c Config) AddRegistryMirror(registry string, endpoints []string) {
+	var hosts strings.Builder
+	switch registry {
+	case defaultRegistry:
+	case "docker.io":
+		hosts.WriteString("server = \"https://registry-1.docker.io\"\n")
+	default:
+		fmt.Fprintf(&hosts, "server = %q\n", "https://"+registry)
+	}
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(&hosts, "\n[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n", endpoint)
+	}
+
+	c.AddFile(File{
+		Path:     path.Join(ContainerdHostsDir, registry, "hosts.toml"),
+		Contents: []byte(hosts.String()),
+	})
+}
+
+// AddTrustedCA adds the PEM encoded CA bundle and a unit which installs it into the trust store of the server
+// before the container runtime and the kubelet start.
+func (c Config) AddTrustedCA(bundle []byte) {
+	c.AddFile(File{Path: TrustedCABundlePath, Contents: bundle})
+	c.AddFile(File{Path: updateCATrustScriptPath, Mode: 0o755, Contents: []byte(updateCATrustScript)})
+	c.AddUnit(Unit{
+		Name:     "metal-update-ca-trust.service",
+		Enabled:  true,
+		Contents: updateCATrustUnit,
+	})
+}
+
+// AddProxy adds drop-ins which configure the HTTP proxy for the ProxyUnits.
+func (c Config) AddProxy(proxy Proxy) {
+	var env []string
+	for _, variable := range []struct{ name, value string }{
+		{"HTTP_PROXY", proxy.HTTPProxy},
+		{"HTTPS_PROXY", proxy.HTTPSProxy},
+		{"NO_PROXY", strings.Join(proxy.NoProxy, ",")},
+	} {
+		if variable.value == "" {
+			continue
+		}
+		// Not every tool honors the upper case variables, hence both spellings are set.
+		env = append(env,
+			"Environment="+systemdQuote(variable.name+"="+variable.value),
+			"Environment="+systemdQuote(strings.ToLower(variable.name)+"="+variable.value),
+		)
+	}
+	if len(env) == 0 {
+		return
+	}
+
+	contents := "[Service]\n" + strings.Join(env, "\n") + "\n"
+	for _, unit := range ProxyUnits {
+		c.AddDropin(unit, Dropin{Name: "http-proxy.conf", Contents: contents})
+	}
+}
+
+// systemdEscaper escapes the characters which are special in double quoted values of unit files, including the
+// specifier character %, see systemd.syntax(7) and systemd.unit(5).
+var systemdEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"%", "%%",
+	"\n", `\n`,
+	"\t", `\t`,
+)
+
+// systemdQuote returns s as double quoted value of a unit file setting.
+func systemdQuote(s string) string {
+	return `"` + systemdEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// PartLabel is set on Secrets which hold a part of a split ignition config.
+	// Its value is the name of the ignition Secret the part belongs to.
+	PartLabel = "infrastructure.cluster.x-k8s.io/ignition-part"
+
+	// PartSecretKeyName is the key of the part config in a part Secret.
+	PartSecretKeyName = "ignition"
+
+	// PartServerCertName and PartServerKeyName are the names of the serving certificate and key of the PartServer
+	// in its certificate directory. PartServerCAName is the CA servers verify the certificate with.
+	PartServerCertName = "tls.crt"
+	PartServerKeyName  = "tls.key"
+	PartServerCAName   = "ca.crt"
+
+	partsPathPrefix = "/ignition-parts"
+)
+
+// PartPath returns the path under which the PartServer serves the given part Secret data.
+// The path contains the digest of the data, so that it can not be guessed from the Secret name alone.
+func PartPath(namespace, name string, data []byte) string {
+	return fmt.Sprintf("%s/%s/%s/%s", partsPathPrefix, namespace, name, digest(data))
+}
+
+// PartServer serves the parts of split ignition configs to servers. The parts contain the certificates and keys
+// of the cluster, hence they are only served via HTTPS.
+type PartServer struct {
+	// Client is used to read the part Secrets.
+	Client client.Reader
+	// BindAddress is the address the server listens on.
+	BindAddress string
+	// CertDir is the directory holding the serving certificate and key, which are reloaded when they change.
+	CertDir string
+	// TLSOpts are applied to the TLS config of the server.
+	TLSOpts []func(*tls.Config)
+}
+
+// Start runs the server until the context is done.
+func (s *PartServer) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("ignition-part-server")
+	if s.CertDir == "" {
+		return errors.New("ignition part server has no certificate directory")
+	}
+
+	watcher, err := certwatcher.New(filepath.Join(s.CertDir, PartServerCertName), filepath.Join(s.CertDir, PartServerKeyName))
+	if err != nil {
+		return fmt.Errorf("failed to load serving certificate of ignition part server: %w", err)
+	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			logger.Error(err, "failed to watch serving certificate of ignition part server")
+		}
+	}()
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: watcher.GetCertificate,
+	}
+	for _, opt := range s.TLSOpts {
+		opt(tlsConfig)
+	}
+
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s.handler(logger),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "failed to shut down ignition part server")
+		}
+	}()
+
+	logger.Info("Starting ignition part server", "BindAddress", s.BindAddress)
+	if err := server.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handler returns the handler serving the part Secrets by their namespace, name and digest.
+func (s *PartServer) handler(logger logr.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+partsPathPrefix+"/{namespace}/{name}/{digest}", func(w http.ResponseWriter, r *http.Request) {
+		data, err := s.getPart(r.Context(), r.PathValue("namespace"), r.PathValue("name"), r.PathValue("digest"))
+		if err != nil {
+			logger.Error(err, "failed to get ignition part", "Path", r.URL.Path)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if data == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(data); err != nil {
+			logger.Error(err, "failed to write ignition part", "Path", r.URL.Path)
+		}
+	})
+	return mux
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, parts are served by every replica.
+func (s *PartServer) NeedLeaderElection() bool {
+	return false
+}
+
+// getPart returns the data of the part Secret, or nil if there is no part Secret with matching digest.
+func (s *PartServer) getPart(ctx context.Context, namespace, name, partDigest string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := s.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, ok := secret.Labels[PartLabel]; !ok {
+		return nil, nil
+	}
+
+	data := secret.Data[PartSecretKeyName]
+	if subtle.ConstantTimeCompare([]byte(digest(data)), []byte(partDigest)) != 1 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("PartServer", func() {
+	partData := []byte(`{"ignition":{"version":"3.4.0"}}`)
+
+	newPartServer := func() *PartServer {
+		return &PartServer{Client: fake.NewClientBuilder().WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ignition-a-part-0", Labels: map[string]string{PartLabel: "ignition-a"}},
+				Data:       map[string][]byte{PartSecretKeyName: partData},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+				Data:       map[string][]byte{PartSecretKeyName: partData},
+			},
+		).Build()}
+	}
+
+	get := func(url string) (int, string) {
+		resp, err := http.Get(url)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, string(body)
+	}
+
+	It("should only serve part Secrets with matching digest", func() {
+		server := httptest.NewServer(newPartServer().handler(logr.Discard()))
+		DeferCleanup(server.Close)
+
+		status, body := get(server.URL + PartPath("default", "ignition-a-part-0", partData))
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal(string(partData)))
+
+		status, _ = get(server.URL + PartPath("default", "ignition-a-part-0", []byte("guessed")))
+		Expect(status).To(Equal(http.StatusNotFound))
+		status, _ = get(server.URL + PartPath("default", "other", partData))
+		Expect(status).To(Equal(http.StatusNotFound))
+		status, _ = get(server.URL + PartPath("default", "missing", partData))
+		Expect(status).To(Equal(http.StatusNotFound))
+	})
+
+	It("should refuse to serve without certificate", func() {
+		Expect(newPartServer().Start(context.Background())).To(MatchError(ContainSubstring("no certificate directory")))
+	})
+
+	It("should serve parts via HTTPS", func(ctx SpecContext) {
+		certDir := GinkgoT().TempDir()
+		caPool := writeServingCertificate(certDir)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		partServer := newPartServer()
+		partServer.BindAddress = address
+		partServer.CertDir = certDir
+		serverCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(partServer.Start(serverCtx)).To(Succeed())
+		}()
+
+		httpsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caPool}}}
+		Eventually(func(g Gomega) {
+			resp, err := httpsClient.Get("https://" + address + PartPath("default", "ignition-a-part-0", partData))
+			g.Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = resp.Body.Close() }()
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		}).Should(Succeed())
+
+		status, body := get("http://" + address + PartPath("default", "ignition-a-part-0", partData))
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).NotTo(ContainSubstring(string(partData)))
+	}, SpecTimeout(10*time.Second))
+})
+
+// writeServingCertificate writes a self-signed serving certificate for 127.0.0.1 into the directory and returns
+// the pool trusting it.
+func writeServingCertificate(dir string) *x509.CertPool {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ignition-parts"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	Expect(os.WriteFile(filepath.Join(dir, PartServerCertName), certPEM, 0o600)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(dir, PartServerKeyName), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)).To(Succeed())
+
+	pool := x509.NewCertPool()
+	Expect(pool.AppendCertsFromPEM(certPEM)).To(BeTrue())
+	return pool
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"slices"
+)
+
+// mergeEntryReserve is the number of bytes reserved in the base config for the merge entry of each part.
+const mergeEntryReserve = 512
+
+// ErrTooLarge is returned if a config can not be split into parts of the requested size.
+var ErrTooLarge = errors.New("ignition config is too large")
+
+// Split moves files out of the config into separate part configs until the config, including the
+// merge entries of the parts, marshals to at most maxSize bytes. Each part marshals to at most maxSize
+// bytes as well. The caller has to add the parts to the config again with AddMergeSource once they
+// are served. Split returns no parts if the config fits already.
+func (c Config) Split(maxSize int) ([]Config, error) {
+	size, err := c.size()
+	if err != nil {
+		return nil, err
+	}
+	if size <= maxSize {
+		return nil, nil
+	}
+
+	files := c.files()
+	sizes := make(map[string]int, len(files))
+	for _, file := range files {
+		s, err := Config(file).size()
+		if err != nil {
+			return nil, err
+		}
+		sizes[filePath(file)] = s
+	}
+	// Move the largest files first, so that as few parts as possible are needed.
+	slices.SortStableFunc(files, func(a, b map[string]any) int {
+		return sizes[filePath(b)] - sizes[filePath(a)]
+	})
+
+	var (
+		parts []Config
+		part  Config
+		moved = map[string]bool{}
+	)
+	partSize := 0
+	for _, file := range files {
+		if size+len(parts)*mergeEntryReserve <= maxSize {
+			break
+		}
+		path := filePath(file)
+		fileSize := sizes[path]
+		if fileSize+mergeEntryReserve > maxSize {
+			return nil, ErrTooLarge
+		}
+		if part == nil || partSize+fileSize > maxSize {
+			part = c.emptyPart()
+			parts = append(parts, part)
+			partSize, _ = part.size()
+		}
+		storage := part.section("storage")
+		storage["files"] = append(storage["files"].([]any), file)
+		partSize += fileSize + 1
+		moved[path] = true
+		size -= fileSize + 1
+	}
+
+	var remaining []any
+	for _, file := range c.files() {
+		if !moved[filePath(file)] {
+			remaining = append(remaining, file)
+		}
+	}
+	c.section("storage")["files"] = remaining
+
+	if size, err = c.size(); err != nil {
+		return nil, err
+	}
+	if size+len(parts)*mergeEntryReserve > maxSize {
+		return nil, ErrTooLarge
+	}
+	return parts, nil
+}
+
+// AddMergeSource adds a config which Ignition fetches from source and merges into this config.
+// The fetched config is verified against the SHA512 hash of data.
+func (c Config) AddMergeSource(source string, data []byte) {
+	key := "merge"
+	if c.IsV2() {
+		key = "append"
+	}
+
+	sum := sha512.Sum512(data)
+	entry := map[string]any{
+		"source": source,
+		"verification": map[string]any{
+			"hash": "sha512-" + hex.EncodeToString(sum[:]),
+		},
+	}
+
+	ignition := c.section("ignition")
+	config, ok := ignition["config"].(map[string]any)
+	if !ok {
+		config = map[string]any{}
+		ignition["config"] = config
+	}
+	config[key] = replaceOrAppend(config[key], "source", source, entry)
+}
+
+// AddCertificateAuthority adds a PEM encoded CA certificate which Ignition trusts when fetching remote configs.
+func (c Config) AddCertificateAuthority(ca []byte) {
+	source := DataURL(ca)
+	ignition := c.section("ignition")
+	security, ok := ignition["security"].(map[string]any)
+	if !ok {
+		security = map[string]any{}
+		ignition["security"] = security
+	}
+	tls, ok := security["tls"].(map[string]any)
+	if !ok {
+		tls = map[string]any{}
+		security["tls"] = tls
+	}
+	tls["certificateAuthorities"] = replaceOrAppend(tls["certificateAuthorities"], "source", source, map[string]any{"source": source})
+}
+
+// emptyPart returns a config without any content which has the same spec version as c.
+func (c Config) emptyPart() Config {
+	return Config{
+		"ignition": map[string]any{"version": c.Version()},
+		"storage":  map[string]any{"files": []any{}},
+	}
+}
+
+func filePath(file map[string]any) string {
+	path, _ := file["path"].(string)
+	return path
+}
+
+func (c Config) size() (int, error) {
+	data, err := c.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
@@ -0,0 +1,314 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	// SupportedVersions are the Ignition spec versions which are accepted by Validate.
+	SupportedVersions = sets.New(
+		"2.0.0", "2.1.0", "2.2.0", "2.3.0",
+		"3.0.0", "3.1.0", "3.2.0", "3.3.0", "3.4.0", "3.5.0",
+	)
+
+	supportedCompressions = sets.New("", "gzip")
+	unitSuffixes          = []string{
+		".service", ".socket", ".target", ".timer", ".mount", ".automount",
+		".path", ".slice", ".scope", ".device", ".swap",
+	}
+)
+
+// Validate checks the config for errors which would make Ignition fail on the server: an unsupported spec
+// version, malformed sections, invalid or duplicate paths, undecodable contents and malformed systemd units.
+func (c Config) Validate() error {
+	var allErrs field.ErrorList
+
+	version := c.Version()
+	if version == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("ignition", "version"), ""))
+	} else if !SupportedVersions.Has(version) {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("ignition", "version"), version, sets.List(SupportedVersions)))
+	}
+
+	allErrs = append(allErrs, c.validateConfigReferences()...)
+	allErrs = append(allErrs, c.validateStorage()...)
+	allErrs = append(allErrs, c.validateSystemd()...)
+	allErrs = append(allErrs, c.validatePasswd()...)
+
+	return allErrs.ToAggregate()
+}
+
+func (c Config) validateConfigReferences() field.ErrorList {
+	var allErrs field.ErrorList
+	ignition, ok := c["ignition"].(map[string]any)
+	if !ok {
+		return append(allErrs, field.Required(field.NewPath("ignition"), ""))
+	}
+	config, ok := ignition["config"].(map[string]any)
+	if !ok {
+		return allErrs
+	}
+
+	fldPath := field.NewPath("ignition", "config")
+	for _, key := range []string{"merge", "append"} {
+		references, errs := objectList(config[key], fldPath.Child(key))
+		allErrs = append(allErrs, errs...)
+		for i, reference := range references {
+			allErrs = append(allErrs, validateSource(reference, fldPath.Child(key).Index(i))...)
+		}
+	}
+	if replace, ok := config["replace"].(map[string]any); ok {
+		allErrs = append(allErrs, validateSource(replace, fldPath.Child("replace"))...)
+	}
+	return allErrs
+}
+
+func (c Config) validateStorage() field.ErrorList {
+	var allErrs field.ErrorList
+	storage, errs := object(c["storage"], field.NewPath("storage"))
+	if storage == nil {
+		return errs
+	}
+
+	paths := sets.New[string]()
+	for _, kind := range []string{"files", "directories", "links"} {
+		fldPath := field.NewPath("storage", kind)
+		entries, errs := objectList(storage[kind], fldPath)
+		allErrs = append(allErrs, errs...)
+		for i, entry := range entries {
+			entryPath := fldPath.Index(i)
+			allErrs = append(allErrs, validatePath(entry["path"], paths, entryPath.Child("path"))...)
+			if c.IsV2() {
+				if filesystem, _ := entry["filesystem"].(string); filesystem == "" {
+					allErrs = append(allErrs, field.Required(entryPath.Child("filesystem"), ""))
+				}
+			}
+			if mode, ok := entry["mode"]; ok && !isFileMode(mode) {
+				allErrs = append(allErrs, field.Invalid(entryPath.Child("mode"), mode, "must be a non-negative integer"))
+			}
+			if kind == "files" {
+				if contents, ok := entry["contents"].(map[string]any); ok {
+					allErrs = append(allErrs, validateContents(contents, entryPath.Child("contents"))...)
+				}
+			}
+		}
+	}
+	return allErrs
+}
+
+func (c Config) validateSystemd() field.ErrorList {
+	var allErrs field.ErrorList
+	systemd, errs := object(c["systemd"], field.NewPath("systemd"))
+	if systemd == nil {
+		return errs
+	}
+
+	fldPath := field.NewPath("systemd", "units")
+	units, errs := objectList(systemd["units"], fldPath)
+	allErrs = append(allErrs, errs...)
+	names := sets.New[string]()
+	for i, unit := range units {
+		unitPath := fldPath.Index(i)
+		name, _ := unit["name"].(string)
+		switch {
+		case name == "":
+			allErrs = append(allErrs, field.Required(unitPath.Child("name"), ""))
+		case !hasUnitSuffix(name):
+			allErrs = append(allErrs, field.Invalid(unitPath.Child("name"), name, "must have a valid unit type suffix"))
+		case names.Has(name):
+			allErrs = append(allErrs, field.Duplicate(unitPath.Child("name"), name))
+		}
+		names.Insert(name)
+
+		if contents, ok := unit["contents"].(string); ok {
+			if err := ValidateUnit(contents); err != nil {
+				allErrs = append(allErrs, field.Invalid(unitPath.Child("contents"), name, err.Error()))
+			}
+		}
+
+		dropins, errs := objectList(unit["dropins"], unitPath.Child("dropins"))
+		allErrs = append(allErrs, errs...)
+		for j, dropin := range dropins {
+			dropinPath := unitPath.Child("dropins").Index(j)
+			dropinName, _ := dropin["name"].(string)
+			if !strings.HasSuffix(dropinName, ".conf") {
+				allErrs = append(allErrs, field.Invalid(dropinPath.Child("name"), dropinName, "must end with .conf"))
+			}
+			if contents, ok := dropin["contents"].(string); ok {
+				if err := ValidateUnit(contents); err != nil {
+					allErrs = append(allErrs, field.Invalid(dropinPath.Child("contents"), dropinName, err.Error()))
+				}
+			}
+		}
+	}
+	return allErrs
+}
+
+func (c Config) validatePasswd() field.ErrorList {
+	var allErrs field.ErrorList
+	passwd, errs := object(c["passwd"], field.NewPath("passwd"))
+	if passwd == nil {
+		return errs
+	}
+
+	fldPath := field.NewPath("passwd", "users")
+	users, errs := objectList(passwd["users"], fldPath)
+	allErrs = append(allErrs, errs...)
+	for i, user := range users {
+		if name, _ := user["name"].(string); name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("name"), ""))
+		}
+	}
+	return allErrs
+}
+
+// ValidateUnit checks that contents follow the systemd unit file syntax.
+func ValidateUnit(contents string) error {
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	scanner.Buffer(nil, len(contents)+1)
+	inSection, continued := false, false
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		wasContinued := continued
+		continued = strings.HasSuffix(line, "\\")
+		switch {
+		case wasContinued, line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") || len(line) < 3 {
+				return fmt.Errorf("line %d: invalid section header %q", lineNo, line)
+			}
+			inSection = true
+		default:
+			if !inSection {
+				return fmt.Errorf("line %d: assignment outside of a section", lineNo)
+			}
+			if key, _, ok := strings.Cut(line, "="); !ok || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("line %d: expected key=value, got %q", lineNo, line)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+func validatePath(value any, seen sets.Set[string], fldPath *field.Path) field.ErrorList {
+	p, ok := value.(string)
+	switch {
+	case !ok || p == "":
+		return field.ErrorList{field.Required(fldPath, "")}
+	case !path.IsAbs(p):
+		return field.ErrorList{field.Invalid(fldPath, p, "must be absolute")}
+	case path.Clean(p) != p:
+		return field.ErrorList{field.Invalid(fldPath, p, "must be a clean path")}
+	case seen.Has(p):
+		return field.ErrorList{field.Duplicate(fldPath, p)}
+	}
+	seen.Insert(p)
+	return nil
+}
+
+func validateContents(contents map[string]any, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	compression, _ := contents["compression"].(string)
+	if !supportedCompressions.Has(compression) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("compression"), compression, sets.List(supportedCompressions)))
+	}
+	if source, ok := contents["source"].(string); ok && strings.HasPrefix(source, "data:") {
+		if _, err := DecodeDataURL(source); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("source"), "<data>", err.Error()))
+		}
+	}
+	return allErrs
+}
+
+func validateSource(reference map[string]any, fldPath *field.Path) field.ErrorList {
+	if source, _ := reference["source"].(string); source == "" {
+		return field.ErrorList{field.Required(fldPath.Child("source"), "")}
+	}
+	return nil
+}
+
+// isFileMode reports whether mode is a non-negative integer, either parsed from JSON or set by AddFile.
+func isFileMode(mode any) bool {
+	switch m := mode.(type) {
+	case float64:
+		return m >= 0 && m == float64(int(m))
+	case int:
+		return m >= 0
+	default:
+		return false
+	}
+}
+
+func hasUnitSuffix(name string) bool {
+	for _, suffix := range unitSuffixes {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// object returns value as JSON object. It returns an error if value is set but not an object.
+func object(value any, fldPath *field.Path) (map[string]any, field.ErrorList) {
+	if value == nil {
+		return nil, nil
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil, field.ErrorList{field.TypeInvalid(fldPath, jsonType(value), "must be an object")}
+	}
+	return obj, nil
+}
+
+// objectList returns value as list of JSON objects. It returns errors for values which are not objects.
+func objectList(value any, fldPath *field.Path) ([]map[string]any, field.ErrorList) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, field.ErrorList{field.TypeInvalid(fldPath, jsonType(value), "must be a list")}
+	}
+	var (
+		objs    []map[string]any
+		allErrs field.ErrorList
+	)
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			allErrs = append(allErrs, field.TypeInvalid(fldPath.Index(i), jsonType(item), "must be an object"))
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs, allErrs
+}
+
+// jsonType returns the JSON type name of a decoded value. It is used instead of the value in errors,
+// as ignition configs carry secrets.
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "list"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ignition
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	DescribeTable("should validate configs",
+		func(data string, errMatcher OmegaMatcher) {
+			config, err := Parse([]byte(data))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Validate()).To(errMatcher)
+		},
+		Entry("valid spec 3 config",
+			`{"ignition":{"version":"3.4.0"},"storage":{"files":[{"path":"/etc/foo","mode":420,"contents":{"source":"data:,foo"}}]},
+			"systemd":{"units":[{"name":"foo.service","contents":"[Unit]\nDescription=Foo\n\n[Service]\nExecStart=/bin/foo \\\n  --bar\n"}]}}`,
+			Succeed()),
+		Entry("valid spec 2 config",
+			`{"ignition":{"version":"2.3.0"},"storage":{"files":[{"filesystem":"root","path":"/etc/foo"}]}}`,
+			Succeed()),
+		Entry("missing version",
+			`{"ignition":{}}`,
+			MatchError(ContainSubstring("ignition.version: Required value"))),
+		Entry("unsupported version",
+			`{"ignition":{"version":"4.0.0"}}`,
+			MatchError(ContainSubstring("ignition.version: Unsupported value"))),
+		Entry("relative file path",
+			`{"ignition":{"version":"3.4.0"},"storage":{"files":[{"path":"etc/foo"}]}}`,
+			MatchError(ContainSubstring("storage.files[0].path: Invalid value: \"etc/foo\": must be absolute"))),
+		Entry("duplicate file path",
+			`{"ignition":{"version":"3.4.0"},"storage":{"files":[{"path":"/etc/foo"}],"directories":[{"path":"/etc/foo"}]}}`,
+			MatchError(ContainSubstring("storage.directories[0].path: Duplicate value"))),
+		Entry("missing filesystem in spec 2",
+			`{"ignition":{"version":"2.3.0"},"storage":{"files":[{"path":"/etc/foo"}]}}`,
+			MatchError(ContainSubstring("storage.files[0].filesystem: Required value"))),
+		Entry("malformed storage",
+			`{"ignition":{"version":"3.4.0"},"storage":{"files":{}}}`,
+			MatchError(ContainSubstring("storage.files: Invalid value: \"object\": must be a list"))),
+		Entry("unit without type suffix",
+			`{"ignition":{"version":"3.4.0"},"systemd":{"units":[{"name":"foo"}]}}`,
+			MatchError(ContainSubstring("systemd.units[0].name: Invalid value"))),
+		Entry("malformed unit",
+			`{"ignition":{"version":"3.4.0"},"systemd":{"units":[{"name":"foo.service","contents":"ExecStart=/bin/foo"}]}}`,
+			MatchError(ContainSubstring("line 1: assignment outside of a section"))),
+		Entry("undecodable data URL",
+			`{"ignition":{"version":"3.4.0"},"storage":{"files":[{"path":"/etc/foo","contents":{"source":"data:;base64,!!"}}]}}`,
+			MatchError(ContainSubstring("storage.files[0].contents.source"))),
+	)
+
+	It("should accept files added to the config", func() {
+		for _, data := range []string{`{"ignition":{"version":"3.4.0"}}`, `{"ignition":{"version":"2.3.0"}}`} {
+			config, err := Parse([]byte(data))
+			Expect(err).NotTo(HaveOccurred())
+			config.AddFile(File{Path: "/etc/foo", Contents: []byte("foo")})
+			Expect(config.Validate()).To(Succeed())
+
+			// The mode of added files is a Go int until the config is marshalled and parsed again.
+			marshalled, err := config.Marshal()
+			Expect(err).NotTo(HaveOccurred())
+			reparsed, err := Parse(marshalled)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reparsed.Validate()).To(Succeed())
+		}
+	})
+})
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry"
+)
+
+const (
+	// cosignSignatureAnnotation is the annotation of signature layers holding the base64 encoded signature.
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// cosignSignatureType is the type of the simple signing payload of cosign signatures.
+	cosignSignatureType = "cosign container image signature"
+)
+
+var (
+	// ErrUntrusted is returned if an image has no valid signature of the trusted keys.
+	ErrUntrusted = errors.New("image is not trusted")
+	// ErrInvalidPublicKey is returned if the trusted keys are not configured correctly.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+)
+
+// simpleSigningPayload is the payload signed by cosign.
+type simpleSigningPayload struct {
+	Critical struct {
+		Type  string `json:"type"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify checks that the image has a cosign signature of one of the PEM encoded public keys and returns the
+// digest the signature was verified for. Signatures are looked up with the cosign tag scheme in the repository
+// of the image.
+func Verify(ctx context.Context, client *registry.Client, image string, publicKeys []string) (string, error) {
+	keys, err := parsePublicKeys(publicKeys)
+	if err != nil {
+		return "", err
+	}
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUntrusted, err)
+	}
+	digest, err := client.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of image %s: %w", image, err)
+	}
+
+	signatureRef := registry.Reference{
+		Registry:   ref.Registry,
+		Repository: ref.Repository,
+		Tag:        strings.Replace(digest, ":", "-", 1) + ".sig",
+	}
+	data, _, err := client.GetManifest(ctx, signatureRef)
+	if errors.Is(err, registry.ErrNotFound) {
+		return "", fmt.Errorf("%w: image %s has no cosign signature", ErrUntrusted, image)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get signatures of image %s: %w", image, err)
+	}
+	manifest := registry.Manifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("%w: failed to parse signature manifest of image %s: %w", ErrUntrusted, image, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		signature, err := base64.StdEncoding.DecodeString(layer.Annotations[cosignSignatureAnnotation])
+		if err != nil || len(signature) == 0 {
+			continue
+		}
+		payload, err := client.GetBlob(ctx, signatureRef, layer.Digest)
+		if err != nil {
+			return "", fmt.Errorf("failed to get signature payload of image %s: %w", image, err)
+		}
+		if verifyPayload(keys, payload, signature, digest) {
+			return digest, nil
+		}
+	}
+	return "", fmt.Errorf("%w: image %s has no valid signature of a trusted key", ErrUntrusted, image)
+}
+
+// verifyPayload reports whether the signature of the payload is valid for one of the keys and the payload
+// refers to the digest.
+func verifyPayload(keys []crypto.PublicKey, payload, signature []byte, digest string) bool {
+	signed := simpleSigningPayload{}
+	if err := json.Unmarshal(payload, &signed); err != nil {
+		return false
+	}
+	if signed.Critical.Type != cosignSignatureType || signed.Critical.Image.DockerManifestDigest != digest {
+		return false
+	}
+	for _, key := range keys {
+		if verifySignature(key, payload, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+func verifySignature(key crypto.PublicKey, payload, signature []byte) bool {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		hash := crypto.SHA256
+		switch key.Curve {
+		case elliptic.P384():
+			hash = crypto.SHA384
+		case elliptic.P521():
+			hash = crypto.SHA512
+		}
+		h := hash.New()
+		h.Write(payload)
+		return ecdsa.VerifyASN1(key, h.Sum(nil), signature)
+	case *rsa.PublicKey:
+		h := crypto.SHA256.New()
+		h.Write(payload)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, h.Sum(nil), signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	default:
+		return false
+	}
+}
+
+func parsePublicKeys(publicKeys []string) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for i, publicKey := range publicKeys {
+		block, _ := pem.Decode([]byte(publicKey))
+		if block == nil {
+			return nil, fmt.Errorf("%w: public key %d is not PEM encoded", ErrInvalidPublicKey, i)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to parse public key %d: %w", ErrInvalidPublicKey, i, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no public key is configured", ErrInvalidPublicKey)
+	}
+	return keys, nil
+}
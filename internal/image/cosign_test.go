@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry/registrytest"
+)
+
+var _ = Describe("Verify", func() {
+	var (
+		reg          *registrytest.Registry
+		client       *registry.Client
+		key          *ecdsa.PrivateKey
+		publicKey    string
+		imageRef     string
+		imageDigest  string
+		signatureTag string
+	)
+
+	BeforeEach(func() {
+		reg = registrytest.New()
+		DeferCleanup(reg.Close)
+		client = &registry.Client{HTTPClient: reg.Client()}
+
+		key, publicKey = generateKey()
+
+		imageDigest = reg.PushManifest("os", "1.31", registry.Manifest{MediaType: "application/vnd.oci.image.manifest.v1+json"})
+		imageRef = reg.Host() + "/os:1.31"
+		signatureTag = "sha256-" + imageDigest[len("sha256:"):] + ".sig"
+	})
+
+	sign := func(signer *ecdsa.PrivateKey, digest string) {
+		payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"%s/os"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, reg.Host(), digest))
+		sum := sha256.Sum256(payload)
+		signature, err := ecdsa.SignASN1(rand.Reader, signer, sum[:])
+		Expect(err).NotTo(HaveOccurred())
+
+		layer := reg.PushBlob("os", payload)
+		layer.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+		layer.Annotations = map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)}
+		reg.PushManifest("os", signatureTag, registry.Manifest{Layers: []registry.Descriptor{layer}})
+	}
+
+	It("should verify a signed image", func(ctx context.Context) {
+		sign(key, imageDigest)
+		Expect(Verify(ctx, client, imageRef, []string{publicKey})).To(Equal(imageDigest))
+	})
+
+	It("should accept a signature of any of the keys", func(ctx context.Context) {
+		sign(key, imageDigest)
+		_, otherKey := generateKey()
+		Expect(Verify(ctx, client, imageRef, []string{otherKey, publicKey})).To(Equal(imageDigest))
+	})
+
+	It("should reject unsigned images", func(ctx context.Context) {
+		_, err := Verify(ctx, client, imageRef, []string{publicKey})
+		Expect(err).To(MatchError(ErrUntrusted))
+		Expect(err).To(MatchError(ContainSubstring("has no cosign signature")))
+	})
+
+	It("should reject signatures of other keys", func(ctx context.Context) {
+		otherKey, _ := generateKey()
+		sign(otherKey, imageDigest)
+		_, err := Verify(ctx, client, imageRef, []string{publicKey})
+		Expect(err).To(MatchError(ErrUntrusted))
+	})
+
+	It("should reject signatures of other digests", func(ctx context.Context) {
+		sign(key, registry.Digest([]byte("other")))
+		_, err := Verify(ctx, client, imageRef, []string{publicKey})
+		Expect(err).To(MatchError(ErrUntrusted))
+	})
+
+	It("should report invalid public keys as configuration error", func(ctx context.Context) {
+		_, err := Verify(ctx, client, imageRef, []string{"invalid"})
+		Expect(err).To(MatchError(ErrInvalidPublicKey))
+		Expect(err).NotTo(MatchError(ErrUntrusted))
+		Expect(err).To(MatchError(ContainSubstring("not PEM encoded")))
+	})
+})
+
+func generateKey() (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package image resolves the OS images IroncoreMetalMachines are provisioned with.
+package image
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var (
+	// ErrNotInCatalog is returned if an image catalog has no image for a Kubernetes version and architecture.
+	ErrNotInCatalog = errors.New("image not in catalog")
+	// ErrUnsupportedArchitecture is returned if there is no image for an architecture.
+	ErrUnsupportedArchitecture = errors.New("unsupported architecture")
+)
+
+// FromCatalog returns the image of the catalog for the Kubernetes version and CPU architecture.
+// The image reference is pinned to the digest of the catalog entry if it has one.
+func FromCatalog(catalog *infrav1.IroncoreMetalImageCatalog, kubernetesVersion, architecture string) (string, error) {
+	version := strings.TrimPrefix(kubernetesVersion, "v")
+	for _, img := range catalog.Spec.Images {
+		arch := img.Architecture
+		if arch == "" {
+			arch = infrav1.DefaultArchitecture
+		}
+		if strings.TrimPrefix(img.KubernetesVersion, "v") == version && arch == architecture {
+			return Pin(img.Image, img.Digest), nil
+		}
+	}
+	return "", fmt.Errorf("%w: IroncoreMetalImageCatalog %s has no image for Kubernetes %s on %s",
+		ErrNotInCatalog, catalog.Name, kubernetesVersion, architecture)
+}
+
+// ForArchitecture returns the image of the CPU architecture.
+func ForArchitecture(images []infrav1.ArchitectureImage, architecture string) (string, error) {
+	for _, img := range images {
+		if img.Architecture == architecture {
+			return img.Image, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no image for %s", ErrUnsupportedArchitecture, architecture)
+}
+
+// Architectures returns the CPU architectures of the images.
+func Architectures(images []infrav1.ArchitectureImage) []string {
+	architectures := make([]string, 0, len(images))
+	for _, img := range images {
+		architectures = append(architectures, img.Architecture)
+	}
+	return architectures
+}
+
+// Pin returns the image reference pinned to the digest. A digest the reference is already pinned to is replaced,
+// the tag is kept for readability. The reference is returned unchanged if digest is empty.
+func Pin(image, digest string) string {
+	if digest == "" {
+		return image
+	}
+	if name, _, ok := strings.Cut(image, "@"); ok {
+		image = name
+	}
+	return image + "@" + digest
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+var _ = Describe("FromCatalog", func() {
+	catalog := &infrav1.IroncoreMetalImageCatalog{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog"},
+		Spec: infrav1.IroncoreMetalImageCatalogSpec{
+			Images: []infrav1.CatalogImage{
+				{KubernetesVersion: "v1.30.2", Image: "registry.example.com/os:1.30"},
+				{KubernetesVersion: "1.31.4", Architecture: "amd64", Image: "registry.example.com/os:1.31", Digest: digest},
+				{KubernetesVersion: "v1.31.4", Architecture: "arm64", Image: "registry.example.com/os:1.31-arm64"},
+			},
+		},
+	}
+
+	DescribeTable("should resolve images",
+		func(version, arch, expected string) {
+			Expect(FromCatalog(catalog, version, arch)).To(Equal(expected))
+		},
+		Entry("default architecture", "v1.30.2", "amd64", "registry.example.com/os:1.30"),
+		Entry("pinned digest", "v1.31.4", "amd64", "registry.example.com/os:1.31@"+digest),
+		Entry("version without v prefix", "1.31.4", "arm64", "registry.example.com/os:1.31-arm64"),
+	)
+
+	It("should fail for versions which are not in the catalog", func() {
+		_, err := FromCatalog(catalog, "v1.32.0", "amd64")
+		Expect(err).To(MatchError(ErrNotInCatalog))
+		Expect(err).To(MatchError(ContainSubstring("has no image for Kubernetes v1.32.0 on amd64")))
+
+		_, err = FromCatalog(catalog, "v1.30.2", "arm64")
+		Expect(err).To(MatchError(ErrNotInCatalog))
+	})
+})
+
+var _ = Describe("ForArchitecture", func() {
+	images := []infrav1.ArchitectureImage{
+		{Architecture: "amd64", Image: "registry.example.com/os:1.31"},
+		{Architecture: "arm64", Image: "registry.example.com/os:1.31-arm64"},
+	}
+
+	It("should return the image of the architecture", func() {
+		Expect(ForArchitecture(images, "arm64")).To(Equal("registry.example.com/os:1.31-arm64"))
+		Expect(Architectures(images)).To(Equal([]string{"amd64", "arm64"}))
+	})
+
+	It("should fail for architectures without image", func() {
+		_, err := ForArchitecture(images, "riscv64")
+		Expect(err).To(MatchError(ErrUnsupportedArchitecture))
+	})
+})
+
+var _ = Describe("Pin", func() {
+	It("should pin image references", func() {
+		Expect(Pin("registry.example.com/os:1.31", digest)).To(Equal("registry.example.com/os:1.31@" + digest))
+		Expect(Pin("registry.example.com/os@sha256:old", digest)).To(Equal("registry.example.com/os@" + digest))
+		Expect(Pin("registry.example.com/os:1.31", "")).To(Equal("registry.example.com/os:1.31"))
+	})
+})
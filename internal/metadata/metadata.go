@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metadata builds the metadata document which is exposed to the OS and node agents of an IroncoreMetalMachine.
+package metadata
+
+import (
+	"encoding/json"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+const (
+	// RoleControlPlane is the role of machines which are part of the control plane.
+	RoleControlPlane = "control-plane"
+	// RoleWorker is the role of all other machines.
+	RoleWorker = "worker"
+)
+
+// Document is the metadata document of an IroncoreMetalMachine.
+type Document struct {
+	// Cluster describes the cluster the machine belongs to.
+	Cluster Cluster `json:"cluster"`
+	// Machine describes the machine itself.
+	Machine Machine `json:"machine"`
+	// Server describes the server bound to the machine. It is omitted as long as the ServerClaim is not bound.
+	Server *Server `json:"server,omitempty"`
+}
+
+// Cluster describes the cluster of a machine.
+type Cluster struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// Machine describes a machine.
+type Machine struct {
+	Name       string `json:"name"`
+	Role       string `json:"role"`
+	Version    string `json:"version,omitempty"`
+	ProviderID string `json:"providerID,omitempty"`
+}
+
+// Server describes the server bound to a machine.
+type Server struct {
+	Name         string            `json:"name"`
+	UUID         string            `json:"uuid"`
+	SerialNumber string            `json:"serialNumber,omitempty"`
+	Manufacturer string            `json:"manufacturer,omitempty"`
+	Model        string            `json:"model,omitempty"`
+	SKU          string            `json:"sku,omitempty"`
+	Rack         string            `json:"rack,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// New builds the metadata document of the given machine. server may be nil if the machine is not bound yet.
+func New(cluster *clusterv1.Cluster, machine *clusterv1.Machine, metalMachine *infrav1.IroncoreMetalMachine, server *metalv1alpha1.Server) Document {
+	doc := Document{
+		Cluster: Cluster{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+		Machine: Machine{
+			Name: machine.Name,
+			Role: RoleWorker,
+		},
+	}
+	if util.IsControlPlaneMachine(machine) {
+		doc.Machine.Role = RoleControlPlane
+	}
+	if machine.Spec.Version != nil {
+		doc.Machine.Version = *machine.Spec.Version
+	}
+	if metalMachine.Spec.ProviderID != nil {
+		doc.Machine.ProviderID = *metalMachine.Spec.ProviderID
+	}
+
+	if server != nil {
+		doc.Server = &Server{
+			Name:         server.Name,
+			UUID:         server.Spec.UUID,
+			SerialNumber: server.Status.SerialNumber,
+			Manufacturer: server.Status.Manufacturer,
+			Model:        server.Status.Model,
+			SKU:          server.Status.SKU,
+			Rack:         server.Labels[infrav1.ServerRackLabel],
+			Labels:       server.Labels,
+		}
+	}
+	return doc
+}
+
+// Marshal returns the JSON representation of the document.
+func (d Document) Marshal() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metadata
+
+import (
+	"encoding/json"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var _ = Describe("Metadata", func() {
+	var (
+		cluster      *clusterv1.Cluster
+		machine      *clusterv1.Machine
+		metalMachine *infrav1.IroncoreMetalMachine
+	)
+
+	BeforeEach(func() {
+		cluster = &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster-a"}}
+		machine = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine-a"},
+			Spec:       clusterv1.MachineSpec{Version: ptr.To("v1.31.1")},
+		}
+		metalMachine = &infrav1.IroncoreMetalMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine-a"}}
+	})
+
+	It("should describe unbound worker machines without server", func() {
+		doc := New(cluster, machine, metalMachine, nil)
+		Expect(doc).To(Equal(Document{
+			Cluster: Cluster{Name: "cluster-a", Namespace: "default"},
+			Machine: Machine{Name: "machine-a", Role: RoleWorker, Version: "v1.31.1"},
+		}))
+
+		data, err := doc.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).NotTo(ContainSubstring(`"server"`))
+	})
+
+	It("should describe control plane machines with their bound server", func() {
+		machine.Labels = map[string]string{clusterv1.MachineControlPlaneLabel: ""}
+		metalMachine.Spec.ProviderID = ptr.To("metal://default/machine-a")
+		server := &metalv1alpha1.Server{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "server-a",
+				Labels: map[string]string{infrav1.ServerRackLabel: "rack-1"},
+			},
+			Spec: metalv1alpha1.ServerSpec{UUID: "38947555-7742-3448-3784-823347823834"},
+			Status: metalv1alpha1.ServerStatus{
+				Manufacturer: "Dell Inc.",
+				Model:        "PowerEdge R650",
+				SKU:          "0R650",
+				SerialNumber: "7QXJ1K3",
+			},
+		}
+
+		doc := New(cluster, machine, metalMachine, server)
+		Expect(doc.Machine).To(Equal(Machine{Name: "machine-a", Role: RoleControlPlane, Version: "v1.31.1", ProviderID: "metal://default/machine-a"}))
+		Expect(doc.Server).To(Equal(&Server{
+			Name:         "server-a",
+			UUID:         "38947555-7742-3448-3784-823347823834",
+			SerialNumber: "7QXJ1K3",
+			Manufacturer: "Dell Inc.",
+			Model:        "PowerEdge R650",
+			SKU:          "0R650",
+			Rack:         "rack-1",
+			Labels:       map[string]string{infrav1.ServerRackLabel: "rack-1"},
+		}))
+
+		data, err := doc.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		var decoded Document
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(doc))
+	})
+})
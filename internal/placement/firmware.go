@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+// Firmware are the firmware versions of a Server. Versions which are not reported are empty.
+type Firmware struct {
+	BIOS string
+	BMC  string
+}
+
+// FirmwareOf returns the firmware versions metal-operator reports for the Server. The BIOS version is taken from
+// the status of the Server, which metal-operator only fills for Servers with BIOS settings for the version they
+// run. The BMC version is taken from the status of the BMC the Server references, bmcFirmware maps the names of the
+// BMCs to their versions, see BMCFirmware.
+func FirmwareOf(server *metalv1alpha1.Server, bmcFirmware map[string]string) Firmware {
+	firmware := Firmware{BIOS: server.Status.BIOS.Version}
+	if server.Spec.BMCRef != nil {
+		firmware.BMC = bmcFirmware[server.Spec.BMCRef.Name]
+	}
+	return firmware
+}
+
+// BMCFirmware maps the names of the BMCs to the firmware versions they report.
+func BMCFirmware(bmcs []metalv1alpha1.BMC) map[string]string {
+	firmware := make(map[string]string, len(bmcs))
+	for _, bmc := range bmcs {
+		if bmc.Status.FirmwareVersion != "" {
+			firmware[bmc.Name] = bmc.Status.FirmwareVersion
+		}
+	}
+	return firmware
+}
+
+// FirmwareViolations returns how the firmware violates the policy and which versions the policy requires are
+// unknown. Unknown versions do not violate the policy, as metal-operator does not report the BIOS version of all
+// Servers.
+func FirmwareViolations(firmware Firmware, policy *infrav1.FirmwarePolicy) (violations, unknown []string) {
+	if policy == nil {
+		return nil, nil
+	}
+	check := func(component, version, minVersion string) {
+		switch {
+		case minVersion == "":
+		case version == "":
+			unknown = append(unknown, fmt.Sprintf("%s version is unknown, at least %s is required", component, minVersion))
+		case CompareVersions(version, minVersion) < 0:
+			violations = append(violations, fmt.Sprintf("%s version %s is older than %s", component, version, minVersion))
+		}
+	}
+	check("BIOS", firmware.BIOS, policy.MinBIOSVersion)
+	check("BMC", firmware.BMC, policy.MinBMCVersion)
+	return violations, unknown
+}
+
+// MergeFirmwarePolicies returns the policy requiring the highest minimum versions of the policies, or nil if none
+// is given.
+func MergeFirmwarePolicies(policies ...*infrav1.FirmwarePolicy) *infrav1.FirmwarePolicy {
+	var merged *infrav1.FirmwarePolicy
+	for _, policy := range policies {
+		if policy == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &infrav1.FirmwarePolicy{}
+		}
+		if CompareVersions(policy.MinBIOSVersion, merged.MinBIOSVersion) > 0 {
+			merged.MinBIOSVersion = policy.MinBIOSVersion
+		}
+		if CompareVersions(policy.MinBMCVersion, merged.MinBMCVersion) > 0 {
+			merged.MinBMCVersion = policy.MinBMCVersion
+		}
+	}
+	return merged
+}
+
+// CompareVersions compares two firmware versions by their components and returns -1, 0 or 1. Numeric components
+// are compared by their values, other components lexically. Missing components are lower than present ones, e.g.
+// 2.1 is older than 2.1.1.
+func CompareVersions(a, b string) int {
+	as, bs := versionComponents(a), versionComponents(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareVersionComponents(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// versionComponents splits the version into its runs of digits and letters, separators are dropped.
+func versionComponents(version string) []string {
+	var components []string
+	var current strings.Builder
+	digits := false
+	for _, r := range version {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if current.Len() > 0 {
+				components = append(components, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		if current.Len() > 0 && unicode.IsDigit(r) != digits {
+			components = append(components, current.String())
+			current.Reset()
+		}
+		digits = unicode.IsDigit(r)
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		components = append(components, current.String())
+	}
+	return components
+}
+
+func compareVersionComponents(a, b string) int {
+	if !isDigits(a) || !isDigits(b) {
+		return strings.Compare(a, b)
+	}
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var _ = Describe("Firmware", func() {
+	DescribeTable("should compare versions by their components",
+		func(a, b string, expected int) {
+			Expect(CompareVersions(a, b)).To(Equal(expected))
+			Expect(CompareVersions(b, a)).To(Equal(-expected))
+		},
+		Entry("numerically", "2.10", "2.9", 1),
+		Entry("with leading zeros", "7.10.30.00", "7.10.30.0", 0),
+		Entry("with missing components", "2.1", "2.1.1", -1),
+		Entry("with letters", "U46 v2.72", "U46 v2.8", 1),
+		Entry("with prefixes", "v1.2", "v1.2", 0),
+		Entry("against no version", "1.0", "", 1),
+	)
+
+	It("should report the violations of the policy", func() {
+		server := newMetalServer()
+		bmcFirmware := BMCFirmware([]metalv1alpha1.BMC{newMetalBMC()})
+		Expect(FirmwareOf(&server, bmcFirmware)).To(Equal(Firmware{BIOS: "U46 v2.72", BMC: "2.78"}))
+
+		policy := &infrav1.FirmwarePolicy{MinBIOSVersion: "U46 v2.70", MinBMCVersion: "2.78"}
+		violations, unknown := FirmwareViolations(FirmwareOf(&server, bmcFirmware), policy)
+		Expect(violations).To(BeEmpty())
+		Expect(unknown).To(BeEmpty())
+		Expect(Request{FirmwarePolicy: policy, BMCFirmware: bmcFirmware}.Matches(&server)).To(BeTrue())
+
+		policy.MinBMCVersion = "3.01"
+		violations, _ = FirmwareViolations(FirmwareOf(&server, bmcFirmware), policy)
+		Expect(violations).To(Equal([]string{"BMC version 2.78 is older than 3.01"}))
+		Expect(Request{FirmwarePolicy: policy, BMCFirmware: bmcFirmware}.Matches(&server)).To(BeFalse())
+	})
+
+	It("should match Servers not reporting their firmware versions", func() {
+		server := newMetalServer()
+		server.Spec.BIOS = nil
+		server.Status.BIOS = metalv1alpha1.BIOSSettings{}
+		policy := &infrav1.FirmwarePolicy{MinBIOSVersion: "U46 v2.70", MinBMCVersion: "2.78"}
+		violations, unknown := FirmwareViolations(FirmwareOf(&server, nil), policy)
+		Expect(violations).To(BeEmpty())
+		Expect(unknown).To(Equal([]string{
+			"BIOS version is unknown, at least U46 v2.70 is required",
+			"BMC version is unknown, at least 2.78 is required",
+		}))
+		Expect(Request{FirmwarePolicy: policy}.Matches(&server)).To(BeTrue())
+	})
+
+	It("should merge policies by their highest minimum versions", func() {
+		Expect(MergeFirmwarePolicies(nil, nil)).To(BeNil())
+		Expect(MergeFirmwarePolicies(
+			&infrav1.FirmwarePolicy{MinBIOSVersion: "2.10"},
+			nil,
+			&infrav1.FirmwarePolicy{MinBIOSVersion: "2.9", MinBMCVersion: "7.1"},
+		)).To(Equal(&infrav1.FirmwarePolicy{MinBIOSVersion: "2.10", MinBMCVersion: "7.1"}))
+	})
+})
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package placement chooses the Servers IroncoreMetalMachines are placed on, for requirements which can not be
+// expressed by the ServerSelector of a ServerClaim.
+package placement
+
+import (
+	"sort"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+// Request describes the Server an IroncoreMetalMachine asks for.
+type Request struct {
+	// Selector selects the Servers by their labels.
+	Selector labels.Selector
+	// AntiAffinity keeps the Server out of the topology domains of the Servers of sibling machines.
+	AntiAffinity []AntiAffinityTerm
+	// Preferences rank the matching Servers.
+	Preferences []Preference
+	// Excluded rules out the Servers any of the selectors select, e.g. the Servers of exhausted quota classes.
+	Excluded []labels.Selector
+	// FirmwarePolicy rules out the Servers running older firmware, see FirmwareViolations.
+	FirmwarePolicy *infrav1.FirmwarePolicy
+	// BMCFirmware maps the names of the BMCs to their firmware versions for the FirmwarePolicy, see BMCFirmware.
+	BMCFirmware map[string]string
+	// Architectures rule out the Servers of other CPU architectures, see ServerArchitecture.
+	Architectures sets.Set[string]
+	// BIOSVersion rules out the Servers reporting another BIOS version. metal-operator only reports the version of
+	// Servers with BIOS settings for it, Servers not reporting a version match.
+	BIOSVersion string
+}
+
+// ServerArchitecture returns the CPU architecture of the Server from its kubernetes.io/arch label. Neither
+// metal-operator nor this provider set the label, so Servers of other architectures than amd64 have to be
+// labeled by the operator of the servers. Unlabeled Servers are considered amd64.
+func ServerArchitecture(server *metalv1alpha1.Server) string {
+	if arch := server.Labels[infrav1.ArchitectureLabel]; arch != "" {
+		return arch
+	}
+	return infrav1.DefaultArchitecture
+}
+
+// Preference adds its weight to the score of the Servers it selects.
+type Preference struct {
+	Weight   int32
+	Selector labels.Selector
+}
+
+// AntiAffinityPenalty is subtracted from the score of a Server for each violated preferred anti-affinity term.
+// It equals the maximum weight of a preference, so that spreading takes precedence over single preferences.
+const AntiAffinityPenalty = 100
+
+// AntiAffinityTerm keeps a Server out of topology domains.
+type AntiAffinityTerm struct {
+	// TopologyKey is the label of Servers whose values denote the topology domains.
+	TopologyKey string
+	// Required rules out Servers in the Domains. Otherwise Servers in other domains are preferred.
+	Required bool
+	// Domains are the topology domains to stay out of.
+	Domains sets.Set[string]
+}
+
+// violatedBy reports whether the Server is in one of the domains of the term. Servers without the topology
+// key are in no domain.
+func (t AntiAffinityTerm) violatedBy(server *metalv1alpha1.Server) bool {
+	domain, ok := server.Labels[t.TopologyKey]
+	return ok && t.Domains.Has(domain)
+}
+
+// Claimable reports whether the Server can be claimed: it is available, not claimed yet and not referenced by
+// another ServerClaim. claimed are the names of the Servers referenced by ServerClaims.
+func Claimable(server *metalv1alpha1.Server, claimed sets.Set[string]) bool {
+	return server.Status.State == metalv1alpha1.ServerStateAvailable &&
+		server.Spec.ServerClaimRef == nil &&
+		!claimed.Has(server.Name)
+}
+
+// Matches reports whether the Server matches the request. Servers not reporting their firmware versions match the
+// firmware policy.
+func (r Request) Matches(server *metalv1alpha1.Server) bool {
+	if r.Selector != nil && !r.Selector.Matches(labels.Set(server.Labels)) {
+		return false
+	}
+	for _, excluded := range r.Excluded {
+		if excluded.Matches(labels.Set(server.Labels)) {
+			return false
+		}
+	}
+	if r.Architectures.Len() > 0 && !r.Architectures.Has(ServerArchitecture(server)) {
+		return false
+	}
+	for _, term := range r.AntiAffinity {
+		if term.Required && term.violatedBy(server) {
+			return false
+		}
+	}
+	if violations, _ := FirmwareViolations(FirmwareOf(server, r.BMCFirmware), r.FirmwarePolicy); len(violations) > 0 {
+		return false
+	}
+	if version := server.Status.BIOS.Version; r.BIOSVersion != "" && version != "" && version != r.BIOSVersion {
+		return false
+	}
+	return true
+}
+
+// Score returns how well the Server fits the request, the higher the better. It is the sum of the weights of the
+// preferences selecting the Server minus the AntiAffinityPenalty of each violated preferred anti-affinity term.
+func (r Request) Score(server *metalv1alpha1.Server) int64 {
+	var score int64
+	for _, preference := range r.Preferences {
+		if preference.Selector.Matches(labels.Set(server.Labels)) {
+			score += int64(preference.Weight)
+		}
+	}
+	for _, term := range r.AntiAffinity {
+		if !term.Required && term.violatedBy(server) {
+			score -= AntiAffinityPenalty
+		}
+	}
+	return score
+}
+
+// Availability counts the Servers matching a request.
+type Availability struct {
+	// Matching is the number of Servers matching the request.
+	Matching int
+	// Available is the number of matching Servers which can be claimed.
+	Available int
+	// Claimed is the number of matching Servers which are claimed already.
+	Claimed int
+}
+
+// Count returns the availability of the Servers matching the request.
+func Count(servers []metalv1alpha1.Server, claimed sets.Set[string], request Request) Availability {
+	availability := Availability{}
+	for i := range servers {
+		server := &servers[i]
+		if !request.Matches(server) {
+			continue
+		}
+		availability.Matching++
+		switch {
+		case Claimable(server, claimed):
+			availability.Available++
+		case server.Spec.ServerClaimRef != nil || claimed.Has(server.Name):
+			availability.Claimed++
+		}
+	}
+	return availability
+}
+
+// Select returns the claimable Server which matches the request and has the highest score together with its
+// score, or nil if there is none. Servers with the same score are considered in the order of their names, so
+// that the choice is stable.
+func Select(servers []metalv1alpha1.Server, claimed sets.Set[string], request Request) (*metalv1alpha1.Server, int64) {
+	candidates := make([]*metalv1alpha1.Server, 0, len(servers))
+	for i := range servers {
+		if Claimable(&servers[i], claimed) && request.Matches(&servers[i]) {
+			candidates = append(candidates, &servers[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, 0
+	}
+	scores := make(map[string]int64, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate.Name] = request.Score(candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if scores[candidates[i].Name] != scores[candidates[j].Name] {
+			return scores[candidates[i].Name] > scores[candidates[j].Name]
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0], scores[candidates[0].Name]
+}
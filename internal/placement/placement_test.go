@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+func newServer(name string, state metalv1alpha1.ServerState, serverLabels map[string]string) metalv1alpha1.Server {
+	return metalv1alpha1.Server{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: serverLabels},
+		Status:     metalv1alpha1.ServerStatus{State: state},
+	}
+}
+
+// newMetalServer returns a Server the way metal-operator registers it: discovered through its BMC, with the BIOS
+// settings for the version it runs applied.
+func newMetalServer() metalv1alpha1.Server {
+	return metalv1alpha1.Server{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "compute-r1-01-system-0",
+			Labels: map[string]string{"rack": "r1"},
+		},
+		Spec: metalv1alpha1.ServerSpec{
+			UUID:   "38947555-7742-3448-3784-823347823834",
+			Power:  metalv1alpha1.PowerOff,
+			BMCRef: &corev1.LocalObjectReference{Name: "compute-r1-01"},
+			BIOS:   []metalv1alpha1.BIOSSettings{{Version: "U46 v2.72", Settings: map[string]string{"BootMode": "Uefi"}}},
+		},
+		Status: metalv1alpha1.ServerStatus{
+			Manufacturer: "HPE",
+			Model:        "ProLiant DL380 Gen10 Plus",
+			SKU:          "P05172-B21",
+			SerialNumber: "CZ2D1234AB",
+			PowerState:   metalv1alpha1.ServerOffPowerState,
+			State:        metalv1alpha1.ServerStateAvailable,
+			NetworkInterfaces: []metalv1alpha1.NetworkInterface{
+				{Name: "eth0", IP: metalv1alpha1.MustParseIP("10.0.1.21"), MACAddress: "b4:7a:f1:2c:3d:4e"},
+			},
+			BIOS: metalv1alpha1.BIOSSettings{Version: "U46 v2.72", Settings: map[string]string{"BootMode": "Uefi"}},
+			Conditions: []metav1.Condition{
+				{Type: "Reboot needed", Status: metav1.ConditionTrue, Reason: "BIOSSettingUpdateNeedReboot", LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+}
+
+// newMetalBMC returns the BMC of the Server of newMetalServer.
+func newMetalBMC() metalv1alpha1.BMC {
+	return metalv1alpha1.BMC{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute-r1-01"},
+		Status: metalv1alpha1.BMCStatus{
+			MACAddress:      "b4:7a:f1:2c:3d:4f",
+			IP:              metalv1alpha1.MustParseIP("10.0.0.21"),
+			Manufacturer:    "HPE",
+			Model:           "iLO 5",
+			FirmwareVersion: "2.78",
+			PowerState:      metalv1alpha1.OnPowerState,
+		},
+	}
+}
+
+var _ = Describe("Select", func() {
+	var servers []metalv1alpha1.Server
+
+	BeforeEach(func() {
+		servers = []metalv1alpha1.Server{
+			newServer("server-d", metalv1alpha1.ServerStateAvailable, map[string]string{"pool": "a"}),
+			newServer("server-c", metalv1alpha1.ServerStateAvailable, map[string]string{"pool": "a"}),
+			newServer("server-b", metalv1alpha1.ServerStateReserved, map[string]string{"pool": "a"}),
+			newServer("server-a", metalv1alpha1.ServerStateAvailable, map[string]string{"pool": "b"}),
+			newServer("server-e", metalv1alpha1.ServerStateAvailable, map[string]string{"pool": "a"}),
+		}
+	})
+
+	It("should select the first matching claimable Server", func() {
+		server, _ := Select(servers, sets.New("server-c"), Request{
+			Selector: labels.SelectorFromSet(labels.Set{"pool": "a"}),
+		})
+		Expect(server).NotTo(BeNil())
+		Expect(server.Name).To(Equal("server-d"))
+	})
+
+	It("should select Servers by name without requirements", func() {
+		server, _ := Select(servers, sets.New[string](), Request{})
+		Expect(server).NotTo(BeNil())
+		Expect(server.Name).To(Equal("server-a"))
+	})
+
+	It("should only select Servers of the requested architectures", func() {
+		servers[3].Labels[infrav1.ArchitectureLabel] = "arm64"
+		server, _ := Select(servers, sets.New[string](), Request{Architectures: sets.New("arm64")})
+		Expect(server).NotTo(BeNil())
+		Expect(server.Name).To(Equal("server-a"))
+
+		// Unlabeled Servers are amd64.
+		server, _ = Select(servers, sets.New[string](), Request{Architectures: sets.New("amd64")})
+		Expect(server).NotTo(BeNil())
+		Expect(server.Name).To(Equal("server-c"))
+	})
+
+	It("should skip Servers which are claimed", func() {
+		servers[3].Spec.ServerClaimRef = &corev1.ObjectReference{Name: "claim"}
+		server, _ := Select(servers, sets.New[string](), Request{Selector: labels.SelectorFromSet(labels.Set{"pool": "b"})})
+		Expect(server).To(BeNil())
+	})
+
+	It("should skip excluded Servers", func() {
+		server, _ := Select(servers, sets.New[string](), Request{
+			Excluded: []labels.Selector{labels.SelectorFromSet(labels.Set{"pool": "b"})},
+		})
+		Expect(server).NotTo(BeNil())
+		Expect(server.Name).To(Equal("server-c"))
+	})
+
+	It("should count matching, available and claimed Servers", func() {
+		servers[2].Spec.ServerClaimRef = &corev1.ObjectReference{Name: "claim"}
+		availability := Count(servers, sets.New("server-d"), Request{Selector: labels.SelectorFromSet(labels.Set{"pool": "a"})})
+		Expect(availability).To(Equal(Availability{Matching: 4, Available: 2, Claimed: 2}))
+	})
+
+	It("should skip Servers reporting another BIOS version", func() {
+		request := Request{BIOSVersion: "2.1"}
+		servers[3].Status.BIOS.Version = "2.0"
+		Expect(request.Matches(&servers[3])).To(BeFalse())
+		servers[3].Status.BIOS.Version = "2.1"
+		Expect(request.Matches(&servers[3])).To(BeTrue())
+		// metal-operator does not report the version without BIOS settings for it.
+		Expect(request.Matches(&servers[4])).To(BeTrue())
+	})
+
+	Context("with anti-affinity", func() {
+		BeforeEach(func() {
+			servers = []metalv1alpha1.Server{
+				newServer("server-a", metalv1alpha1.ServerStateAvailable, map[string]string{infrav1.ServerRackLabel: "rack-1"}),
+				newServer("server-b", metalv1alpha1.ServerStateAvailable, map[string]string{infrav1.ServerRackLabel: "rack-1"}),
+				newServer("server-c", metalv1alpha1.ServerStateAvailable, map[string]string{infrav1.ServerRackLabel: "rack-2"}),
+			}
+		})
+
+		It("should rule out used domains if required", func() {
+			term := AntiAffinityTerm{TopologyKey: infrav1.ServerRackLabel, Required: true, Domains: sets.New("rack-1")}
+			server, _ := Select(servers, sets.New[string](), Request{AntiAffinity: []AntiAffinityTerm{term}})
+			Expect(server).NotTo(BeNil())
+			Expect(server.Name).To(Equal("server-c"))
+
+			term.Domains.Insert("rack-2")
+			server, _ = Select(servers, sets.New[string](), Request{AntiAffinity: []AntiAffinityTerm{term}})
+			Expect(server).To(BeNil())
+		})
+
+		It("should prefer unused domains if preferred", func() {
+			term := AntiAffinityTerm{TopologyKey: infrav1.ServerRackLabel, Domains: sets.New("rack-1", "rack-2")}
+			server, _ := Select(servers, sets.New[string](), Request{AntiAffinity: []AntiAffinityTerm{term}})
+			Expect(server).NotTo(BeNil())
+			Expect(server.Name).To(Equal("server-a"))
+
+			term.Domains = sets.New("rack-1")
+			server, _ = Select(servers, sets.New[string](), Request{AntiAffinity: []AntiAffinityTerm{term}})
+			Expect(server).NotTo(BeNil())
+			Expect(server.Name).To(Equal("server-c"))
+		})
+
+		It("should not consider Servers without topology key in a domain", func() {
+			server := newServer("server-d", metalv1alpha1.ServerStateAvailable, nil)
+			term := AntiAffinityTerm{TopologyKey: infrav1.ServerRackLabel, Required: true, Domains: sets.New("rack-1")}
+			Expect(Request{AntiAffinity: []AntiAffinityTerm{term}}.Matches(&server)).To(BeTrue())
+		})
+	})
+
+	Context("with preferences", func() {
+		BeforeEach(func() {
+			servers = []metalv1alpha1.Server{
+				newServer("server-a", metalv1alpha1.ServerStateAvailable, map[string]string{"generation": "gen9", infrav1.ServerRackLabel: "rack-1"}),
+				newServer("server-b", metalv1alpha1.ServerStateAvailable, map[string]string{"generation": "gen10", infrav1.ServerRackLabel: "rack-1"}),
+				newServer("server-c", metalv1alpha1.ServerStateAvailable, map[string]string{"generation": "gen11", infrav1.ServerRackLabel: "rack-2"}),
+			}
+		})
+
+		preferences := []Preference{
+			{Weight: 80, Selector: labels.SelectorFromSet(labels.Set{"generation": "gen11"})},
+			{Weight: 50, Selector: labels.SelectorFromSet(labels.Set{"generation": "gen10"})},
+		}
+
+		It("should select the Server with the highest score", func() {
+			server, score := Select(servers, sets.New[string](), Request{Preferences: preferences})
+			Expect(server).NotTo(BeNil())
+			Expect(server.Name).To(Equal("server-c"))
+			Expect(score).To(Equal(int64(80)))
+		})
+
+		It("should accept Servers no preference selects", func() {
+			server, score := Select(servers, sets.New("server-b", "server-c"), Request{Preferences: preferences})
+			Expect(server).NotTo(BeNil())
+			Expect(server.Name).To(Equal("server-a"))
+			Expect(score).To(BeZero())
+		})
+
+		It("should penalize violated preferred anti-affinity terms", func() {
+			term := AntiAffinityTerm{TopologyKey: infrav1.ServerRackLabel, Domains: sets.New("rack-2")}
+			server, score := Select(servers, sets.New[string](), Request{Preferences: preferences, AntiAffinity: []AntiAffinityTerm{term}})
+			Expect(server).NotTo(BeNil())
+			Expect(server.Name).To(Equal("server-b"))
+			Expect(score).To(Equal(int64(50)))
+		})
+	})
+})
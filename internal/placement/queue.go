@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Candidate is an IroncoreMetalMachine waiting for a Server.
+type Candidate struct {
+	// Name is the namespaced name of the IroncoreMetalMachine.
+	Name types.NamespacedName
+	// ControlPlane reports whether the IroncoreMetalMachine belongs to a control plane.
+	ControlPlane bool
+	// Priority is the effective priority of the IroncoreMetalMachine.
+	Priority int32
+	// CreationTimestamp is the creation time of the IroncoreMetalMachine.
+	CreationTimestamp metav1.Time
+	// Request describes the Servers the IroncoreMetalMachine can claim.
+	Request Request
+}
+
+// Ahead reports whether c is admitted before other: control plane machines before workers, then machines of
+// higher priority, then older machines. The name breaks ties, so that the order is stable.
+func (c Candidate) Ahead(other Candidate) bool {
+	switch {
+	case c.ControlPlane != other.ControlPlane:
+		return c.ControlPlane
+	case c.Priority != other.Priority:
+		return c.Priority > other.Priority
+	case !c.CreationTimestamp.Equal(&other.CreationTimestamp):
+		return c.CreationTimestamp.Before(&other.CreationTimestamp)
+	case c.Name.Namespace != other.Name.Namespace:
+		return c.Name.Namespace < other.Name.Namespace
+	default:
+		return c.Name.Name < other.Name.Name
+	}
+}
+
+// QueuePosition returns the position of the candidate among the waiting candidates competing for the same Servers,
+// starting at 1. Waiting candidates compete with the candidate if they match any of the claimable Servers it
+// matches. The candidate itself is skipped if it is one of the waiting candidates.
+func QueuePosition(candidate Candidate, waiting []Candidate, servers []metalv1alpha1.Server, claimed sets.Set[string]) int {
+	var candidateServers []*metalv1alpha1.Server
+	for i := range servers {
+		if Claimable(&servers[i], claimed) && candidate.Request.Matches(&servers[i]) {
+			candidateServers = append(candidateServers, &servers[i])
+		}
+	}
+
+	position := 1
+	for _, other := range waiting {
+		if other.Name == candidate.Name || !other.Ahead(candidate) {
+			continue
+		}
+		for _, server := range candidateServers {
+			if other.Request.Matches(server) {
+				position++
+				break
+			}
+		}
+	}
+	return position
+}
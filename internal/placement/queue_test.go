@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"time"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var _ = Describe("Queue", func() {
+	now := time.Now()
+	newCandidate := func(name string, controlPlane bool, priority int32, age time.Duration, pool string) Candidate {
+		return Candidate{
+			Name:              types.NamespacedName{Namespace: "default", Name: name},
+			ControlPlane:      controlPlane,
+			Priority:          priority,
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			Request:           Request{Selector: labels.SelectorFromSet(labels.Set{"pool": pool})},
+		}
+	}
+	servers := []metalv1alpha1.Server{
+		newServer("server-a", metalv1alpha1.ServerStateAvailable, map[string]string{"pool": "a"}),
+		newServer("server-b", metalv1alpha1.ServerStateAvailable, map[string]string{"pool": "b"}),
+	}
+
+	It("should order control planes, priorities and ages", func() {
+		worker := newCandidate("worker", false, 100, time.Hour, "a")
+		Expect(newCandidate("cp", true, 0, 0, "a").Ahead(worker)).To(BeTrue())
+		Expect(newCandidate("high", false, 200, 0, "a").Ahead(worker)).To(BeTrue())
+		Expect(newCandidate("old", false, 100, 2*time.Hour, "a").Ahead(worker)).To(BeTrue())
+		Expect(newCandidate("young", false, 100, 0, "a").Ahead(worker)).To(BeFalse())
+		Expect(worker.Ahead(worker)).To(BeFalse())
+	})
+
+	It("should count the competing candidates ahead", func() {
+		candidate := newCandidate("worker", false, 0, time.Hour, "a")
+		waiting := []Candidate{
+			candidate,
+			newCandidate("cp", true, 0, 0, "a"),
+			newCandidate("high", false, 10, 0, "a"),
+			newCandidate("other-pool", true, 10, 0, "b"),
+			newCandidate("low", false, -10, 2*time.Hour, "a"),
+		}
+		Expect(QueuePosition(candidate, waiting, servers, sets.New[string]())).To(Equal(3))
+	})
+
+	It("should ignore candidates competing for claimed Servers only", func() {
+		candidate := newCandidate("worker", false, 0, time.Hour, "a")
+		waiting := []Candidate{newCandidate("cp", true, 0, 0, "a")}
+		Expect(QueuePosition(candidate, waiting, servers, sets.New("server-a"))).To(Equal(1))
+	})
+})
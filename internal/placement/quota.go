@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+// QuotaUsage returns the usage of the quota by the ServerClaims of a cluster. ServerClaims count against a class
+// once they reference a Server matching its selector.
+func QuotaUsage(quota *infrav1.ServerQuota, claims []metalv1alpha1.ServerClaim, servers []metalv1alpha1.Server) (infrav1.ServerQuotaUsage, error) {
+	usage := infrav1.ServerQuotaUsage{Servers: int32(len(claims))}
+	serversByName := make(map[string]*metalv1alpha1.Server, len(servers))
+	for i := range servers {
+		serversByName[servers[i].Name] = &servers[i]
+	}
+
+	for _, class := range quota.Classes {
+		selector, err := metav1.LabelSelectorAsSelector(&class.ServerSelector)
+		if err != nil {
+			return infrav1.ServerQuotaUsage{}, fmt.Errorf("invalid ServerSelector of quota class %s: %w", class.Name, err)
+		}
+		classUsage := infrav1.ServerQuotaClassUsage{Name: class.Name}
+		for _, claim := range claims {
+			if claim.Spec.ServerRef == nil {
+				continue
+			}
+			if server, ok := serversByName[claim.Spec.ServerRef.Name]; ok && selector.Matches(labels.Set(server.Labels)) {
+				classUsage.Servers++
+			}
+		}
+		usage.Classes = append(usage.Classes, classUsage)
+	}
+	return usage, nil
+}
+
+// ExhaustedClasses returns the classes of the quota whose Servers are all used.
+func ExhaustedClasses(quota *infrav1.ServerQuota, usage infrav1.ServerQuotaUsage) []infrav1.ServerQuotaClass {
+	used := make(map[string]int32, len(usage.Classes))
+	for _, class := range usage.Classes {
+		used[class.Name] = class.Servers
+	}
+	var exhausted []infrav1.ServerQuotaClass
+	for _, class := range quota.Classes {
+		if used[class.Name] >= class.MaxServers {
+			exhausted = append(exhausted, class)
+		}
+	}
+	return exhausted
+}
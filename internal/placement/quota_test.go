@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	metalv1alpha1 "github.com/ironcore-dev/metal-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var _ = Describe("Quota", func() {
+	servers := []metalv1alpha1.Server{
+		newServer("server-a", metalv1alpha1.ServerStateReserved, map[string]string{"class": "gpu"}),
+		newServer("server-b", metalv1alpha1.ServerStateReserved, map[string]string{"class": "cpu"}),
+		newServer("server-c", metalv1alpha1.ServerStateAvailable, map[string]string{"class": "gpu"}),
+	}
+	claims := []metalv1alpha1.ServerClaim{
+		{Spec: metalv1alpha1.ServerClaimSpec{ServerRef: &corev1.LocalObjectReference{Name: "server-a"}}},
+		{Spec: metalv1alpha1.ServerClaimSpec{ServerRef: &corev1.LocalObjectReference{Name: "server-b"}}},
+		{Spec: metalv1alpha1.ServerClaimSpec{}},
+	}
+	quota := &infrav1.ServerQuota{
+		MaxServers: ptr.To[int32](5),
+		Classes: []infrav1.ServerQuotaClass{
+			{Name: "gpu", ServerSelector: metav1.LabelSelector{MatchLabels: map[string]string{"class": "gpu"}}, MaxServers: 1},
+			{Name: "cpu", ServerSelector: metav1.LabelSelector{MatchLabels: map[string]string{"class": "cpu"}}, MaxServers: 4},
+		},
+	}
+
+	It("should count the ServerClaims overall and per class of their Servers", func() {
+		usage, err := QuotaUsage(quota, claims, servers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage).To(Equal(infrav1.ServerQuotaUsage{
+			Servers: 3,
+			Classes: []infrav1.ServerQuotaClassUsage{{Name: "gpu", Servers: 1}, {Name: "cpu", Servers: 1}},
+		}))
+	})
+
+	It("should return the classes whose Servers are all used", func() {
+		usage, err := QuotaUsage(quota, claims, servers)
+		Expect(err).NotTo(HaveOccurred())
+		exhausted := ExhaustedClasses(quota, usage)
+		Expect(exhausted).To(HaveLen(1))
+		Expect(exhausted[0].Name).To(Equal("gpu"))
+	})
+
+	It("should reject invalid selectors", func() {
+		_, err := QuotaUsage(&infrav1.ServerQuota{Classes: []infrav1.ServerQuotaClass{{
+			Name:           "invalid",
+			ServerSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "class", Operator: "Invalid"}}},
+		}}}, claims, servers)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// AndSelectors returns a label selector selecting the objects all of the selectors select. Nil selectors select
+// everything and are skipped, the result is nil if all selectors are nil. Match labels of different values for the
+// same key are kept as match expressions, so that the result selects nothing instead of dropping one of them.
+func AndSelectors(selectors ...*metav1.LabelSelector) *metav1.LabelSelector {
+	var result *metav1.LabelSelector
+	for _, selector := range selectors {
+		if selector == nil {
+			continue
+		}
+		if result == nil {
+			result = &metav1.LabelSelector{}
+		}
+		for key, value := range selector.MatchLabels {
+			current, ok := result.MatchLabels[key]
+			switch {
+			case !ok:
+				if result.MatchLabels == nil {
+					result.MatchLabels = map[string]string{}
+				}
+				result.MatchLabels[key] = value
+			case current != value:
+				result.MatchExpressions = append(result.MatchExpressions, metav1.LabelSelectorRequirement{
+					Key:      key,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{value},
+				})
+			}
+		}
+		for _, requirement := range selector.MatchExpressions {
+			result.MatchExpressions = append(result.MatchExpressions, *requirement.DeepCopy())
+		}
+	}
+	return result
+}
+
+// keyConstraint collects the requirements of label selectors on a label key.
+type keyConstraint struct {
+	mustExist    bool
+	mustNotExist bool
+	// allowed are the values the label may have, nil if any value is allowed.
+	allowed  sets.Set[string]
+	excluded sets.Set[string]
+}
+
+func (c *keyConstraint) allow(values ...string) {
+	c.mustExist = true
+	if c.allowed == nil {
+		c.allowed = sets.New(values...)
+		return
+	}
+	c.allowed = c.allowed.Intersection(sets.New(values...))
+}
+
+// conflicts reports whether no label value satisfies the constraint.
+func (c *keyConstraint) conflicts() bool {
+	return (c.mustExist && c.mustNotExist) || (c.allowed != nil && c.allowed.Difference(c.excluded).Len() == 0)
+}
+
+// CheckSelectorsCompatible returns an error if the selectors together can not select any object, because they
+// require a label key to have values which exclude each other, or to exist and not to exist at the same time.
+func CheckSelectorsCompatible(selectors ...*metav1.LabelSelector) error {
+	constraints := map[string]*keyConstraint{}
+	constraint := func(key string) *keyConstraint {
+		if _, ok := constraints[key]; !ok {
+			constraints[key] = &keyConstraint{excluded: sets.New[string]()}
+		}
+		return constraints[key]
+	}
+
+	for _, selector := range selectors {
+		if selector == nil {
+			continue
+		}
+		for key, value := range selector.MatchLabels {
+			constraint(key).allow(value)
+		}
+		for _, requirement := range selector.MatchExpressions {
+			c := constraint(requirement.Key)
+			switch requirement.Operator {
+			case metav1.LabelSelectorOpIn:
+				c.allow(requirement.Values...)
+			case metav1.LabelSelectorOpNotIn:
+				c.excluded.Insert(requirement.Values...)
+			case metav1.LabelSelectorOpExists:
+				c.mustExist = true
+			case metav1.LabelSelectorOpDoesNotExist:
+				c.mustNotExist = true
+			default:
+				return fmt.Errorf("invalid operator %q of label %s", requirement.Operator, requirement.Key)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(constraints))
+	for key := range constraints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if constraints[key].conflicts() {
+			return fmt.Errorf("the selectors have conflicting requirements for label %s", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package placement
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var _ = Describe("AndSelectors", func() {
+	matches := func(selector *metav1.LabelSelector, set labels.Set) bool {
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		Expect(err).NotTo(HaveOccurred())
+		return s.Matches(set)
+	}
+
+	It("should return nil if all selectors are nil", func() {
+		Expect(AndSelectors(nil, nil)).To(BeNil())
+	})
+
+	It("should select the objects all selectors select", func() {
+		selector := AndSelectors(
+			&metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+			nil,
+			&metav1.LabelSelector{
+				MatchLabels: map[string]string{"rack": "r1"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}},
+				},
+			},
+		)
+		Expect(selector.MatchLabels).To(Equal(map[string]string{"pool": "gpu", "rack": "r1"}))
+		Expect(matches(selector, labels.Set{"pool": "gpu", "rack": "r1", "zone": "a"})).To(BeTrue())
+		Expect(matches(selector, labels.Set{"pool": "gpu", "rack": "r1", "zone": "c"})).To(BeFalse())
+		Expect(matches(selector, labels.Set{"pool": "cpu", "rack": "r1", "zone": "a"})).To(BeFalse())
+	})
+
+	It("should select nothing for conflicting match labels", func() {
+		selector := AndSelectors(
+			&metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+			&metav1.LabelSelector{MatchLabels: map[string]string{"pool": "cpu"}},
+		)
+		Expect(matches(selector, labels.Set{"pool": "gpu"})).To(BeFalse())
+		Expect(matches(selector, labels.Set{"pool": "cpu"})).To(BeFalse())
+	})
+})
+
+var _ = Describe("CheckSelectorsCompatible", func() {
+	It("should accept selectors which select common objects", func() {
+		Expect(CheckSelectorsCompatible(
+			&metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			nil,
+			&metav1.LabelSelector{
+				MatchLabels: map[string]string{"pool": "gpu"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tenant", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}},
+					{Key: "tenant", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"b"}},
+					{Key: "maintenance", Operator: metav1.LabelSelectorOpDoesNotExist},
+				},
+			},
+		)).To(Succeed())
+	})
+
+	It("should reject different values of the same label", func() {
+		Expect(CheckSelectorsCompatible(
+			&metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			&metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}},
+		)).To(MatchError(ContainSubstring("label tenant")))
+	})
+
+	It("should reject excluded values", func() {
+		Expect(CheckSelectorsCompatible(
+			&metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tenant", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"a"}},
+			}},
+		)).NotTo(Succeed())
+	})
+
+	It("should reject labels which must and must not exist", func() {
+		Expect(CheckSelectorsCompatible(
+			&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tenant", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+			&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tenant", Operator: metav1.LabelSelectorOpExists},
+			}},
+		)).NotTo(Succeed())
+	})
+})
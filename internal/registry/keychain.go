@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Credential is a username and password or identity token for a registry.
+type Credential struct {
+	Username string
+	Password string
+}
+
+// Keychain provides the credentials for registries.
+type Keychain interface {
+	// Lookup returns the credential for the registry. ok is false if there is none.
+	Lookup(registry string) (Credential, bool)
+}
+
+// DockerConfig is a Keychain with the credentials of a docker config.json, as found in Secrets of type
+// kubernetes.io/dockerconfigjson.
+type DockerConfig map[string]Credential
+
+// ParseDockerConfig parses a docker config.json. Registries are keyed by host, URLs like https://index.docker.io/v1/
+// are normalized.
+func ParseDockerConfig(data []byte) (DockerConfig, error) {
+	var config struct {
+		Auths map[string]struct {
+			Auth          string `json:"auth"`
+			Username      string `json:"username"`
+			Password      string `json:"password"`
+			IdentityToken string `json:"identitytoken"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config: %w", err)
+	}
+
+	keychain := DockerConfig{}
+	for server, auth := range config.Auths {
+		credential := Credential{Username: auth.Username, Password: auth.Password}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode auth of registry %s: %w", server, err)
+			}
+			credential.Username, credential.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		if auth.IdentityToken != "" {
+			credential.Password = auth.IdentityToken
+		}
+		keychain[registryHost(server)] = credential
+	}
+	return keychain, nil
+}
+
+// Lookup implements Keychain.
+func (c DockerConfig) Lookup(registry string) (Credential, bool) {
+	credential, ok := c[registryHost(registry)]
+	return credential, ok
+}
+
+// DockerConfigFile is a Keychain reading the docker config.json at the path on every lookup, so that rotated
+// credentials of mounted Secrets are picked up. A missing or invalid file provides no credentials.
+type DockerConfigFile string
+
+// Lookup implements Keychain.
+func (f DockerConfigFile) Lookup(registry string) (Credential, bool) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return Credential{}, false
+	}
+	config, err := ParseDockerConfig(data)
+	if err != nil {
+		return Credential{}, false
+	}
+	return config.Lookup(registry)
+}
+
+// MultiKeychain looks up credentials in its keychains in order.
+type MultiKeychain []Keychain
+
+// Lookup implements Keychain.
+func (m MultiKeychain) Lookup(registry string) (Credential, bool) {
+	for _, keychain := range m {
+		if keychain == nil {
+			continue
+		}
+		if credential, ok := keychain.Lookup(registry); ok {
+			return credential, true
+		}
+	}
+	return Credential{}, false
+}
+
+// registryHost normalizes the registry keys of docker configs, which may be URLs, to hosts.
+func registryHost(server string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "index.docker.io", dockerHubHost:
+		return DockerHub
+	}
+	return host
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DockerHub is the registry of image references without registry host.
+	DockerHub = "docker.io"
+
+	dockerHubHost = "registry-1.docker.io"
+)
+
+var (
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegexp        = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRegexp     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// Reference is a parsed OCI image reference.
+type Reference struct {
+	// Registry is the registry host including an optional port, e.g. ghcr.io or localhost:5000.
+	Registry string
+	// Repository is the repository within the registry, e.g. ironcore-dev/os-images/gardenlinux.
+	Repository string
+	// Tag is the tag of the image. It is empty if the reference only has a digest.
+	Tag string
+	// Digest is the digest of the image. It is empty if the reference is not pinned.
+	Digest string
+}
+
+// ParseReference parses an image reference like ghcr.io/ironcore-dev/os-images/gardenlinux:1443.3 or
+// localhost:5000/os@sha256:.... References without tag and digest refer to the latest tag.
+func ParseReference(image string) (Reference, error) {
+	ref := Reference{}
+	rest := image
+	if name, digest, ok := strings.Cut(rest, "@"); ok {
+		if !digestRegexp.MatchString(digest) {
+			return Reference{}, fmt.Errorf("invalid digest %q in image reference %q", digest, image)
+		}
+		rest, ref.Digest = name, digest
+	}
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		rest, ref.Tag = rest[:i], rest[i+1:]
+		if !tagRegexp.MatchString(ref.Tag) {
+			return Reference{}, fmt.Errorf("invalid tag %q in image reference %q", ref.Tag, image)
+		}
+	}
+
+	ref.Registry, ref.Repository = DockerHub, rest
+	if host, path, ok := strings.Cut(rest, "/"); ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Registry, ref.Repository = host, path
+	}
+	if ref.Registry == DockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if !repositoryRegexp.MatchString(ref.Repository) {
+		return Reference{}, fmt.Errorf("invalid repository %q in image reference %q", ref.Repository, image)
+	}
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// Host returns the host the registry API is served at.
+func (r Reference) Host() string {
+	if r.Registry == DockerHub {
+		return dockerHubHost
+	}
+	return r.Registry
+}
+
+// Identifier returns the digest of the reference, or its tag if it is not pinned.
+func (r Reference) Identifier() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// String returns the reference in its canonical form.
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
@@ -0,0 +1,301 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package registry implements the parts of the OCI distribution API which are needed to resolve and verify
+// the OS images of IroncoreMetalMachines: fetching manifests and blobs with anonymous, basic or token
+// authentication.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxManifestSize is the maximum size of manifests read from a registry.
+	MaxManifestSize = 4 * 1024 * 1024
+	// MaxBlobSize is the maximum size of blobs read from a registry.
+	MaxBlobSize = 4 * 1024 * 1024
+)
+
+// ManifestMediaTypes are the manifest media types accepted from registries.
+var ManifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// basicAuth is stored instead of a token for registries which use basic authentication.
+const basicAuth = "\x00basic"
+
+// DefaultTimeout is the timeout of requests of clients without HTTPClient.
+const DefaultTimeout = 30 * time.Second
+
+// ErrNotFound is returned if a manifest or blob does not exist.
+var ErrNotFound = errors.New("not found")
+
+// defaultHTTPClient is used by clients without HTTPClient, so that unresponsive registries do not block reconciles.
+var defaultHTTPClient = &http.Client{Timeout: DefaultTimeout}
+
+// Client is a client of OCI registries.
+type Client struct {
+	// HTTPClient is used for all requests. A client with DefaultTimeout is used if it is nil.
+	HTTPClient *http.Client
+	// Keychain provides the credentials for registries. Registries are accessed anonymously without credentials.
+	Keychain Keychain
+	// PlainHTTPRegistries are the registries which are accessed via plain HTTP, e.g. local registries without TLS.
+	PlainHTTPRegistries []string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// WithKeychain returns a client which uses the keychain before the keychain of c.
+func (c *Client) WithKeychain(keychain Keychain) *Client {
+	return &Client{
+		HTTPClient:          c.HTTPClient,
+		Keychain:            MultiKeychain{keychain, c.Keychain},
+		PlainHTTPRegistries: c.PlainHTTPRegistries,
+	}
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	MediaType string       `json:"mediaType,omitempty"`
+	Layers    []Descriptor `json:"layers,omitempty"`
+}
+
+// Descriptor describes a blob of a manifest.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Resolve returns the digest of the manifest the reference points to.
+func (c *Client) Resolve(ctx context.Context, ref Reference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	_, digest, err := c.GetManifest(ctx, ref)
+	return digest, err
+}
+
+// GetManifest returns the raw manifest the reference points to and its digest.
+func (c *Client) GetManifest(ctx context.Context, ref Reference) ([]byte, string, error) {
+	resp, err := c.get(ctx, ref, "manifests/"+ref.Identifier(), strings.Join(ManifestMediaTypes, ", "))
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := readAll(resp.Body, MaxManifestSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read manifest of %s: %w", ref, err)
+	}
+	digest := Digest(data)
+	if ref.Digest != "" && ref.Digest != digest {
+		return nil, "", fmt.Errorf("manifest of %s has digest %s", ref, digest)
+	}
+	return data, digest, nil
+}
+
+// GetBlob returns the blob with the given digest from the repository of the reference.
+func (c *Client) GetBlob(ctx context.Context, ref Reference, digest string) ([]byte, error) {
+	resp, err := c.get(ctx, ref, "blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := readAll(resp.Body, MaxBlobSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s of %s: %w", digest, ref.Repository, err)
+	}
+	if actual := Digest(data); actual != digest {
+		return nil, fmt.Errorf("blob %s of %s has digest %s", digest, ref.Repository, actual)
+	}
+	return data, nil
+}
+
+// Digest returns the sha256 digest of data in OCI notation.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (c *Client) get(ctx context.Context, ref Reference, path, accept string) (*http.Response, error) {
+	scheme := "https"
+	if slices.Contains(c.PlainHTTPRegistries, ref.Registry) {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, ref.Host(), ref.Repository, path)
+	do := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.authorize(req, ref)
+		return c.httpClient().Do(req)
+	}
+
+	resp, err := do()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		if err := c.authenticate(ctx, ref, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = do(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%s of %s: %w", path, ref.Repository, ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s of %s: unexpected status %s", path, ref.Repository, resp.Status)
+	}
+	return resp, nil
+}
+
+// authorize adds the bearer token of the repository, or the basic credentials of the registry if the registry
+// asked for basic authentication before.
+func (c *Client) authorize(req *http.Request, ref Reference) {
+	switch token := c.token(ref); {
+	case token == basicAuth:
+		if credential, ok := c.credential(ref); ok {
+			req.SetBasicAuth(credential.Username, credential.Password)
+		}
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// authenticate requests a bearer token for pulling from the repository of the reference, as described by the
+// WWW-Authenticate challenge of the registry. Registries asking for basic authentication are sent the
+// credentials of the keychain.
+func (c *Client) authenticate(ctx context.Context, ref Reference, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	credential, hasCredential := c.credential(ref)
+	switch {
+	case strings.EqualFold(scheme, "basic") && hasCredential:
+		c.setToken(ref, basicAuth)
+		return nil
+	case !strings.EqualFold(scheme, "bearer") || params["realm"] == "":
+		return fmt.Errorf("registry %s requires unsupported authentication %q", ref.Registry, scheme)
+	}
+
+	query := url.Values{}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.Repository))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if hasCredential {
+		req.SetBasicAuth(credential.Username, credential.Password)
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request token of registry %s: %w", ref.Registry, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to request token of registry %s: unexpected status %s", ref.Registry, resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxManifestSize)).Decode(&token); err != nil {
+		return fmt.Errorf("failed to decode token of registry %s: %w", ref.Registry, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	c.setToken(ref, token.Token)
+	return nil
+}
+
+func (c *Client) credential(ref Reference) (Credential, bool) {
+	if c.Keychain == nil {
+		return Credential{}, false
+	}
+	return c.Keychain.Lookup(ref.Registry)
+}
+
+func (c *Client) setToken(ref Reference, token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = map[string]string{}
+	}
+	c.tokens[ref.Registry+"/"+ref.Repository] = token
+}
+
+func (c *Client) token(ref Reference) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[ref.Registry+"/"+ref.Repository]
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
+// parseChallenge parses a WWW-Authenticate header like Bearer realm="https://auth.example.com/token",service="x".
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return scheme, params
+}
+
+func readAll(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("exceeds %d bytes", limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package registry_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry/registrytest"
+)
+
+const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+var _ = Describe("ParseReference", func() {
+	DescribeTable("should parse references",
+		func(image string, expected registry.Reference) {
+			Expect(registry.ParseReference(image)).To(Equal(expected))
+		},
+		Entry("registry with tag", "ghcr.io/ironcore-dev/os-images/gardenlinux:1443.3",
+			registry.Reference{Registry: "ghcr.io", Repository: "ironcore-dev/os-images/gardenlinux", Tag: "1443.3"}),
+		Entry("registry with port and digest", "localhost:5000/os@"+digest,
+			registry.Reference{Registry: "localhost:5000", Repository: "os", Digest: digest}),
+		Entry("tag and digest", "registry.example.com/os:1.31@"+digest,
+			registry.Reference{Registry: "registry.example.com", Repository: "os", Tag: "1.31", Digest: digest}),
+		Entry("docker hub library image", "alpine",
+			registry.Reference{Registry: "docker.io", Repository: "library/alpine", Tag: "latest"}),
+		Entry("docker hub image", "ironcore/os:1",
+			registry.Reference{Registry: "docker.io", Repository: "ironcore/os", Tag: "1"}),
+	)
+
+	DescribeTable("should reject invalid references",
+		func(image string) {
+			_, err := registry.ParseReference(image)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("invalid digest", "ghcr.io/os@sha256:1234"),
+		Entry("invalid tag", "ghcr.io/os:-tag"),
+		Entry("upper case repository", "ghcr.io/OS:1"),
+	)
+
+	It("should return the host of docker hub", func() {
+		ref, err := registry.ParseReference("alpine")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref.Host()).To(Equal("registry-1.docker.io"))
+		Expect(ref.String()).To(Equal("docker.io/library/alpine:latest"))
+	})
+})
+
+var _ = Describe("Client", func() {
+	var (
+		reg    *registrytest.Registry
+		client *registry.Client
+	)
+
+	BeforeEach(func() {
+		reg = registrytest.New()
+		DeferCleanup(reg.Close)
+		client = &registry.Client{HTTPClient: reg.Client()}
+	})
+
+	It("should resolve tags to digests with token authentication", func(ctx context.Context) {
+		reg.RequireAuth = true
+		manifestDigest := reg.PushManifest("os", "1.31", registry.Manifest{MediaType: "application/vnd.oci.image.manifest.v1+json"})
+
+		ref, err := registry.ParseReference(reg.Host() + "/os:1.31")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Resolve(ctx, ref)).To(Equal(manifestDigest))
+	})
+
+	It("should request tokens with the credentials of the keychain", func(ctx context.Context) {
+		reg.RequireAuth = true
+		reg.Credential = &registry.Credential{Username: "user", Password: "secret"}
+		manifestDigest := reg.PushManifest("os", "1.31", registry.Manifest{MediaType: "application/vnd.oci.image.manifest.v1+json"})
+
+		ref, err := registry.ParseReference(reg.Host() + "/os:1.31")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Resolve(ctx, ref)
+		Expect(err).To(HaveOccurred())
+
+		client = client.WithKeychain(registry.DockerConfig{reg.Host(): *reg.Credential})
+		Expect(client.Resolve(ctx, ref)).To(Equal(manifestDigest))
+	})
+
+	It("should use basic authentication with the credentials of the keychain", func(ctx context.Context) {
+		reg.BasicAuth = true
+		reg.Credential = &registry.Credential{Username: "user", Password: "secret"}
+		manifestDigest := reg.PushManifest("os", "1.31", registry.Manifest{MediaType: "application/vnd.oci.image.manifest.v1+json"})
+
+		client.Keychain = registry.DockerConfig{reg.Host(): *reg.Credential}
+		ref, err := registry.ParseReference(reg.Host() + "/os:1.31")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Resolve(ctx, ref)).To(Equal(manifestDigest))
+	})
+
+	It("should access plain HTTP registries", func(ctx context.Context) {
+		local := registrytest.NewPlainHTTP()
+		DeferCleanup(local.Close)
+		manifestDigest := local.PushManifest("os", "1.31", registry.Manifest{MediaType: "application/vnd.oci.image.manifest.v1+json"})
+
+		client = &registry.Client{PlainHTTPRegistries: []string{local.Host()}}
+		ref, err := registry.ParseReference(local.Host() + "/os:1.31")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Resolve(ctx, ref)).To(Equal(manifestDigest))
+	})
+
+	It("should return blobs", func(ctx context.Context) {
+		descriptor := reg.PushBlob("os", []byte("blob"))
+
+		ref, err := registry.ParseReference(reg.Host() + "/os:1.31")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.GetBlob(ctx, ref, descriptor.Digest)).To(Equal([]byte("blob")))
+	})
+
+	It("should return ErrNotFound for missing manifests", func(ctx context.Context) {
+		ref, err := registry.ParseReference(reg.Host() + "/os:missing")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Resolve(ctx, ref)
+		Expect(err).To(MatchError(registry.ErrNotFound))
+	})
+})
+
+var _ = Describe("DockerConfig", func() {
+	It("should parse the credentials of a docker config.json", func() {
+		config, err := registry.ParseDockerConfig([]byte(`{"auths":{
+			"https://index.docker.io/v1/":{"auth":"dXNlcjpzZWNyZXQ="},
+			"ghcr.io":{"username":"bot","password":"token"},
+			"localhost:5000":{"identitytoken":"identity"}
+		}}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(config).To(Equal(registry.DockerConfig{
+			"docker.io":      {Username: "user", Password: "secret"},
+			"ghcr.io":        {Username: "bot", Password: "token"},
+			"localhost:5000": {Password: "identity"},
+		}))
+		credential, ok := config.Lookup("index.docker.io")
+		Expect(ok).To(BeTrue())
+		Expect(credential).To(Equal(registry.Credential{Username: "user", Password: "secret"}))
+		_, ok = config.Lookup("quay.io")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should look up credentials in order", func() {
+		keychain := registry.MultiKeychain{
+			registry.DockerConfig{"ghcr.io": {Username: "machine"}},
+			registry.DockerConfig{"ghcr.io": {Username: "default"}, "quay.io": {Username: "default"}},
+		}
+		credential, _ := keychain.Lookup("ghcr.io")
+		Expect(credential).To(Equal(registry.Credential{Username: "machine"}))
+		credential, _ = keychain.Lookup("quay.io")
+		Expect(credential).To(Equal(registry.Credential{Username: "default"}))
+	})
+})
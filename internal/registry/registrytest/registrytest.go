@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package registrytest provides an in-memory OCI registry for tests.
+package registrytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/registry"
+)
+
+// Token is the bearer token the registry hands out if it requires authentication.
+const Token = "test-token"
+
+// Registry is an in-memory OCI registry served over TLS.
+type Registry struct {
+	*httptest.Server
+
+	// RequireAuth makes the registry require a bearer token, which is handed out by its token endpoint.
+	RequireAuth bool
+	// BasicAuth makes the registry require basic authentication with Credential instead of a bearer token.
+	BasicAuth bool
+	// Credential is required by the token endpoint, or by the registry itself if BasicAuth is set.
+	Credential *registry.Credential
+
+	mu        sync.Mutex
+	manifests map[string][]byte
+	blobs     map[string][]byte
+}
+
+// New starts a new registry. It must be closed by the caller.
+func New() *Registry {
+	r := newRegistry()
+	r.Server = httptest.NewTLSServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// NewPlainHTTP starts a new registry served over plain HTTP like local registries. It must be closed by the caller.
+func NewPlainHTTP() *Registry {
+	r := newRegistry()
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+func newRegistry() *Registry {
+	return &Registry{
+		manifests: map[string][]byte{},
+		blobs:     map[string][]byte{},
+	}
+}
+
+// Host returns the host of the registry to be used in image references.
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(strings.TrimPrefix(r.URL, "https://"), "http://")
+}
+
+// PushManifest stores the manifest under the tag, which may be empty, and its digest. It returns the digest.
+func (r *Registry) PushManifest(repository, tag string, manifest any) string {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		panic(err)
+	}
+	digest := registry.Digest(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifests[repository+"/"+digest] = data
+	if tag != "" {
+		r.manifests[repository+"/"+tag] = data
+	}
+	return digest
+}
+
+// PushBlob stores the blob and returns its descriptor.
+func (r *Registry) PushBlob(repository string, data []byte) registry.Descriptor {
+	digest := registry.Digest(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blobs[repository+"/"+digest] = data
+	return registry.Descriptor{Digest: digest, Size: int64(len(data))}
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if !r.hasCredential(req) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"token":%q}`, Token)
+		return
+	}
+	switch {
+	case r.BasicAuth && !r.hasCredential(req):
+		w.Header().Set("WWW-Authenticate", `Basic realm="registrytest"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	case r.RequireAuth && req.Header.Get("Authorization") != "Bearer "+Token:
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registrytest"`, r.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	var store map[string][]byte
+	if i := strings.LastIndex(path, "/manifests/"); i >= 0 {
+		path, store = path[:i]+"/"+path[i+len("/manifests/"):], r.manifests
+	} else if i := strings.LastIndex(path, "/blobs/"); i >= 0 {
+		path, store = path[:i]+"/"+path[i+len("/blobs/"):], r.blobs
+	}
+
+	r.mu.Lock()
+	data, ok := store[path]
+	r.mu.Unlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", registry.Digest(data))
+	_, _ = w.Write(data)
+}
+
+func (r *Registry) hasCredential(req *http.Request) bool {
+	if r.Credential == nil {
+		return true
+	}
+	username, password, ok := req.BasicAuth()
+	return ok && username == r.Credential.Username && password == r.Credential.Password
+}
@@ -8,10 +8,13 @@ import (
 
 	"github.com/go-logr/logr"
 	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
 	"github.com/ironcore-dev/metal-operator/api/v1alpha1"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -37,6 +40,11 @@ type MachineScope struct {
 	IroncoreMetalCluster *infrav1.IroncoreMetalCluster
 	IroncoreMetalMachine *infrav1.IroncoreMetalMachine
 	ServerClaim          *v1alpha1.ServerClaim
+	// IroncoreMetalMachineClass is the class of the IroncoreMetalMachine, if it has one.
+	IroncoreMetalMachineClass *infrav1.IroncoreMetalMachineClass
+	// Settings are the settings of the IroncoreMetalMachine including the ones inherited from its class and the
+	// IroncoreMetalCluster.
+	Settings infrav1.MachineSettings
 }
 
 // NewMachineScope creates a new Scope from the supplied parameters.
@@ -69,6 +77,7 @@ func NewMachineScope(params MachineScopeParams) (*MachineScope, error) {
 		Machine:              params.Machine,
 		IroncoreMetalCluster: params.IroncoreMetalCluster,
 		IroncoreMetalMachine: params.IroncoreMetalMachine,
+		Settings:             MachineSettings(params.IroncoreMetalMachine, nil, params.IroncoreMetalCluster),
 	}
 
 	helper, err := patch.NewHelper(params.IroncoreMetalMachine, params.Client)
@@ -81,6 +90,99 @@ func NewMachineScope(params MachineScopeParams) (*MachineScope, error) {
 	return machineScope, nil
 }
 
+// MachineSettings returns the settings of the IroncoreMetalMachine. Fields it leaves empty are inherited from its
+// IroncoreMetalMachineClass, then from the control plane or worker MachineDefaults of the IroncoreMetalCluster. The
+// ServerSelector of the class is combined with the one of the machine or the defaults, the firmware policies of the
+// class, the defaults and the cluster are merged. The images are inherited as a whole, so that an image of the
+// machine is never mixed with images of the class or the defaults.
+func MachineSettings(ironcoremetalmachine *infrav1.IroncoreMetalMachine, class *infrav1.IroncoreMetalMachineClass, ironcoremetalcluster *infrav1.IroncoreMetalCluster) infrav1.MachineSettings {
+	spec := ironcoremetalmachine.Spec.DeepCopy()
+	settings := infrav1.MachineSettings{
+		Image:              spec.Image,
+		Images:             spec.Images,
+		ImageCatalogRef:    spec.ImageCatalogRef,
+		ImagePullSecretRef: spec.ImagePullSecretRef,
+		ServerSelector:     spec.ServerSelector,
+		BIOSSettings:       spec.BIOSSettings,
+		Metadata:           spec.Metadata,
+		BootstrapDataMode:  spec.BootstrapDataMode,
+	}
+
+	var classSelector *metav1.LabelSelector
+	var firmwarePolicies []*infrav1.FirmwarePolicy
+	if class != nil {
+		classSpec := class.Spec.DeepCopy()
+		classSelector = classSpec.ServerSelector
+		firmwarePolicies = append(firmwarePolicies, classSpec.FirmwarePolicy)
+		inheritSettings(&settings, &infrav1.MachineSettings{
+			Image:           classSpec.Image,
+			Images:          classSpec.Images,
+			ImageCatalogRef: classSpec.ImageCatalogRef,
+			BIOSSettings:    classSpec.BIOSSettings,
+		})
+	}
+	if ironcoremetalcluster != nil && ironcoremetalcluster.Spec.MachineDefaults != nil {
+		defaults := ironcoremetalcluster.Spec.MachineDefaults.Worker
+		if _, ok := ironcoremetalmachine.Labels[clusterv1.MachineControlPlaneLabel]; ok {
+			defaults = ironcoremetalcluster.Spec.MachineDefaults.ControlPlane
+		}
+		if defaults != nil {
+			inheritSettings(&settings, defaults.DeepCopy())
+			firmwarePolicies = append(firmwarePolicies, defaults.FirmwarePolicy)
+		}
+	}
+	if ironcoremetalcluster != nil {
+		firmwarePolicies = append(firmwarePolicies, ironcoremetalcluster.Spec.FirmwarePolicy)
+	}
+	if classSelector != nil {
+		settings.ServerSelector = placement.AndSelectors(classSelector, settings.ServerSelector)
+	}
+	settings.FirmwarePolicy = placement.MergeFirmwarePolicies(firmwarePolicies...)
+	return settings
+}
+
+// inheritSettings sets the empty fields of the settings to the ones of the defaults.
+func inheritSettings(settings, defaults *infrav1.MachineSettings) {
+	if settings.Image == "" && len(settings.Images) == 0 && settings.ImageCatalogRef == nil {
+		settings.Image = defaults.Image
+		settings.Images = defaults.Images
+		settings.ImageCatalogRef = defaults.ImageCatalogRef
+	}
+	if settings.ImagePullSecretRef == nil {
+		settings.ImagePullSecretRef = defaults.ImagePullSecretRef
+	}
+	if settings.ServerSelector == nil {
+		settings.ServerSelector = defaults.ServerSelector
+	}
+	if settings.BIOSSettings == nil {
+		settings.BIOSSettings = defaults.BIOSSettings
+	}
+	if settings.Metadata == nil {
+		settings.Metadata = defaults.Metadata
+	}
+	if settings.BootstrapDataMode == "" {
+		settings.BootstrapDataMode = defaults.BootstrapDataMode
+	}
+}
+
+// SetMachineClass sets the IroncoreMetalMachineClass of the IroncoreMetalMachine and the settings inherited from it.
+// Once the ServerClaim of the IroncoreMetalMachine exists, the effective settings recorded in its status are kept, so
+// that changes of the class or the MachineDefaults of the IroncoreMetalCluster do not change a placed machine.
+func (m *MachineScope) SetMachineClass(class *infrav1.IroncoreMetalMachineClass) {
+	m.IroncoreMetalMachineClass = class
+	if m.SettingsFrozen() {
+		m.Settings = *m.IroncoreMetalMachine.Status.EffectiveSettings.DeepCopy()
+		return
+	}
+	m.Settings = MachineSettings(m.IroncoreMetalMachine, class, m.IroncoreMetalCluster)
+}
+
+// SettingsFrozen reports whether the effective settings recorded in the status of the IroncoreMetalMachine are kept,
+// which is the case once its ServerClaim exists.
+func (m *MachineScope) SettingsFrozen() bool {
+	return m.IroncoreMetalMachine.Status.EffectiveSettings != nil && conditions.IsTrue(m.IroncoreMetalMachine, infrav1.ServerAvailableCondition)
+}
+
 // SetReady sets the IroncoreMetalMachine Ready Status.
 func (m *MachineScope) SetReady() {
 	m.IroncoreMetalMachine.Status.Ready = true
@@ -109,7 +211,15 @@ func (m *MachineScope) HasFailed() bool {
 // PatchObject persists the Machine configuration and status.
 func (s *MachineScope) PatchObject() error {
 	// always update the readyCondition.
-	// TBD readyCondition
+	conditions.SetSummary(s.IroncoreMetalMachine,
+		conditions.WithConditions(
+			infrav1.ImageResolvedCondition,
+			infrav1.ImageVerifiedCondition,
+			infrav1.IgnitionReadyCondition,
+			infrav1.ServerAvailableCondition,
+			infrav1.BIOSSettingsAppliedCondition,
+		),
+	)
 
 	return s.patchHelper.Patch(context.TODO(), s.IroncoreMetalMachine)
 }
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package scope
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var _ = Describe("MachineSettings", func() {
+	var (
+		machine *infrav1.IroncoreMetalMachine
+		cluster *infrav1.IroncoreMetalCluster
+	)
+
+	BeforeEach(func() {
+		machine = &infrav1.IroncoreMetalMachine{}
+		cluster = &infrav1.IroncoreMetalCluster{
+			Spec: infrav1.IroncoreMetalClusterSpec{
+				MachineDefaults: &infrav1.MachineDefaults{
+					ControlPlane: &infrav1.MachineSettings{
+						Image:          "registry.example.com/os:control-plane",
+						ServerSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "control-plane"}},
+					},
+					Worker: &infrav1.MachineSettings{
+						ImageCatalogRef:    &corev1.LocalObjectReference{Name: "catalog"},
+						ImagePullSecretRef: &corev1.LocalObjectReference{Name: "pull"},
+						ServerSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}},
+						Metadata:           &infrav1.MetadataSpec{IgnitionPath: "/etc/metal/metadata.json"},
+						BootstrapDataMode:  infrav1.BootstrapDataModePassthrough,
+					},
+				},
+			},
+		}
+	})
+
+	It("should inherit the worker defaults", func() {
+		Expect(MachineSettings(machine, nil, cluster)).To(Equal(*cluster.Spec.MachineDefaults.Worker))
+	})
+
+	It("should inherit the control plane defaults", func() {
+		machine.Labels = map[string]string{clusterv1.MachineControlPlaneLabel: ""}
+		Expect(MachineSettings(machine, nil, cluster)).To(Equal(*cluster.Spec.MachineDefaults.ControlPlane))
+	})
+
+	It("should keep the fields set on the machine", func() {
+		machine.Spec.ServerSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "a"}}
+		machine.Spec.BootstrapDataMode = infrav1.BootstrapDataModeConvert
+		settings := MachineSettings(machine, nil, cluster)
+		Expect(settings.ServerSelector).To(Equal(machine.Spec.ServerSelector))
+		Expect(settings.BootstrapDataMode).To(Equal(infrav1.BootstrapDataModeConvert))
+		Expect(settings.ImagePullSecretRef).To(Equal(&corev1.LocalObjectReference{Name: "pull"}))
+	})
+
+	It("should not mix the images of the machine with the defaults", func() {
+		machine.Spec.Images = []infrav1.ArchitectureImage{{Architecture: "arm64", Image: "registry.example.com/os:arm64"}}
+		settings := MachineSettings(machine, nil, cluster)
+		Expect(settings.Images).To(Equal(machine.Spec.Images))
+		Expect(settings.ImageCatalogRef).To(BeNil())
+	})
+
+	It("should not share the defaults of the cluster", func() {
+		settings := MachineSettings(machine, nil, cluster)
+		settings.ServerSelector.MatchLabels["role"] = "changed"
+		Expect(cluster.Spec.MachineDefaults.Worker.ServerSelector.MatchLabels).To(HaveKeyWithValue("role", "worker"))
+	})
+
+	Context("with an IroncoreMetalMachineClass", func() {
+		var class *infrav1.IroncoreMetalMachineClass
+
+		BeforeEach(func() {
+			class = &infrav1.IroncoreMetalMachineClass{
+				Spec: infrav1.IroncoreMetalMachineClassSpec{
+					ServerSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"size": "large"}},
+					BIOSSettings:   &infrav1.BIOSSettings{Settings: map[string]string{"ProcVirtualization": "Enabled"}},
+					Image:          "registry.example.com/os:large",
+				},
+			}
+		})
+
+		It("should prefer the class over the defaults", func() {
+			settings := MachineSettings(machine, class, cluster)
+			Expect(settings.Image).To(Equal("registry.example.com/os:large"))
+			Expect(settings.ImageCatalogRef).To(BeNil())
+			Expect(settings.BIOSSettings).To(Equal(class.Spec.BIOSSettings))
+			Expect(settings.ImagePullSecretRef).To(Equal(&corev1.LocalObjectReference{Name: "pull"}))
+		})
+
+		It("should combine the selector of the class with the one of the machine or the defaults", func() {
+			Expect(MachineSettings(machine, class, cluster).ServerSelector).To(Equal(&metav1.LabelSelector{
+				MatchLabels: map[string]string{"size": "large", "role": "worker"},
+			}))
+
+			machine.Spec.ServerSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"size": "small"}}
+			Expect(MachineSettings(machine, class, cluster).ServerSelector).To(Equal(&metav1.LabelSelector{
+				MatchLabels:      map[string]string{"size": "large"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "size", Operator: metav1.LabelSelectorOpIn, Values: []string{"small"}}},
+			}))
+		})
+
+		It("should prefer the machine over the class", func() {
+			machine.Spec.ImageCatalogRef = &corev1.LocalObjectReference{Name: "machine"}
+			machine.Spec.BIOSSettings = &infrav1.BIOSSettings{Settings: map[string]string{"SriovGlobalEnable": "Enabled"}}
+			settings := MachineSettings(machine, class, cluster)
+			Expect(settings.BIOSSettings).To(Equal(machine.Spec.BIOSSettings))
+			Expect(settings.ImageCatalogRef).To(Equal(machine.Spec.ImageCatalogRef))
+			Expect(settings.Image).To(BeEmpty())
+		})
+	})
+
+	It("should keep the effective settings once the ServerClaim exists", func() {
+		machineScope := &MachineScope{IroncoreMetalMachine: machine, IroncoreMetalCluster: cluster}
+		machineScope.SetMachineClass(nil)
+		machine.Status.EffectiveSettings = machineScope.Settings.DeepCopy()
+		conditions.MarkTrue(machine, infrav1.ServerAvailableCondition)
+
+		cluster.Spec.MachineDefaults.Worker.ImageCatalogRef = &corev1.LocalObjectReference{Name: "changed"}
+		machineScope.SetMachineClass(nil)
+		Expect(machineScope.Settings.ImageCatalogRef).To(Equal(&corev1.LocalObjectReference{Name: "catalog"}))
+
+		conditions.MarkFalse(machine, infrav1.ServerAvailableCondition, infrav1.WaitingForServersReason, clusterv1.ConditionSeverityWarning, "")
+		machineScope.SetMachineClass(nil)
+		Expect(machineScope.Settings.ImageCatalogRef).To(Equal(&corev1.LocalObjectReference{Name: "changed"}))
+	})
+
+	It("should return the settings of the machine without cluster", func() {
+		machine.Spec.Image = "registry.example.com/os:latest"
+		Expect(MachineSettings(machine, nil, nil)).To(Equal(infrav1.MachineSettings{Image: "registry.example.com/os:latest"}))
+	})
+})
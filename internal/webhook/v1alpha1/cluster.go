@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+)
+
+// clusterNameOf returns the name of the Cluster the object belongs to, which is taken from its cluster name label,
+// its owning Cluster or the Cluster of its owning Machine. It is empty if the object does not belong to a Cluster
+// yet, the update associating it with a Cluster is validated.
+func clusterNameOf(ctx context.Context, c client.Client, obj client.Object) (string, error) {
+	if name, ok := obj.GetLabels()[clusterv1.ClusterNameLabel]; ok {
+		return name, nil
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != clusterv1.GroupVersion.Group {
+			continue
+		}
+		switch ref.Kind {
+		case "Cluster":
+			return ref.Name, nil
+		case "Machine":
+			machine := &clusterv1.Machine{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: ref.Name}, machine); err != nil {
+				return "", client.IgnoreNotFound(err)
+			}
+			return machine.Spec.ClusterName, nil
+		}
+	}
+	return "", nil
+}
+
+// clusterSelectorConflicts returns an error for each IroncoreMetalCluster of the Cluster whose ServerSelector
+// conflicts with the selector at the path.
+func clusterSelectorConflicts(ctx context.Context, c client.Client, namespace, clusterName string, path *field.Path, selector *metav1.LabelSelector) (field.ErrorList, error) {
+	if clusterName == "" || selector == nil {
+		return nil, nil
+	}
+	clusterList := &infrav1alpha1.IroncoreMetalClusterList{}
+	if err := c.List(ctx, clusterList, client.InNamespace(namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		return nil, fmt.Errorf("failed to list IroncoreMetalClusters of cluster %s: %w", clusterName, err)
+	}
+
+	var allErrs field.ErrorList
+	for _, cluster := range clusterList.Items {
+		if err := placement.CheckSelectorsCompatible(cluster.Spec.ServerSelector, selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, selector,
+				fmt.Sprintf("conflicts with the ServerSelector of IroncoreMetalCluster %s: %v", cluster.Name, err)))
+		}
+	}
+	return allErrs, nil
+}
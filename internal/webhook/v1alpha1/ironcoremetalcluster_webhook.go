@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+	"github.com/ironcore-dev/cluster-api-provider-ironcore-metal/internal/placement"
+)
+
+// SetupIroncoreMetalClusterWebhookWithManager registers the webhook for IroncoreMetalCluster in the manager.
+func SetupIroncoreMetalClusterWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&infrav1alpha1.IroncoreMetalCluster{}).
+		WithValidator(&IroncoreMetalClusterCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1alpha1-ironcoremetalcluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalclusters,verbs=update,versions=v1alpha1,name=vironcoremetalcluster-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// IroncoreMetalClusterCustomValidator validates changes of the ServerSelector of IroncoreMetalClusters against the
+// IroncoreMetalMachines and IroncoreMetalMachineTemplates of their cluster.
+type IroncoreMetalClusterCustomValidator struct {
+	Client client.Client
+}
+
+var _ webhook.CustomValidator = &IroncoreMetalClusterCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalCluster.
+// The machines of a new cluster are validated when they are created.
+func (v *IroncoreMetalClusterCustomValidator) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalCluster.
+func (v *IroncoreMetalClusterCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCluster, ok := oldObj.(*infrav1alpha1.IroncoreMetalCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an IroncoreMetalCluster object for the oldObj but got %T", oldObj)
+	}
+	ironcoremetalcluster, ok := newObj.(*infrav1alpha1.IroncoreMetalCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an IroncoreMetalCluster object for the newObj but got %T", newObj)
+	}
+	if equality.Semantic.DeepEqual(oldCluster.Spec.ServerSelector, ironcoremetalcluster.Spec.ServerSelector) {
+		return nil, nil
+	}
+	return nil, v.validate(ctx, ironcoremetalcluster)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalCluster.
+func (v *IroncoreMetalClusterCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects a ServerSelector which conflicts with the ServerSelector of an IroncoreMetalMachine or an
+// IroncoreMetalMachineTemplate of the cluster.
+func (v *IroncoreMetalClusterCustomValidator) validate(ctx context.Context, ironcoremetalcluster *infrav1alpha1.IroncoreMetalCluster) error {
+	clusterName, err := clusterNameOf(ctx, v.Client, ironcoremetalcluster)
+	if err != nil || clusterName == "" {
+		return err
+	}
+
+	machineList := &infrav1alpha1.IroncoreMetalMachineList{}
+	if err := v.Client.List(ctx, machineList, client.InNamespace(ironcoremetalcluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		return fmt.Errorf("failed to list IroncoreMetalMachines of cluster %s: %w", clusterName, err)
+	}
+	templateList := &infrav1alpha1.IroncoreMetalMachineTemplateList{}
+	if err := v.Client.List(ctx, templateList, client.InNamespace(ironcoremetalcluster.Namespace)); err != nil {
+		return fmt.Errorf("failed to list IroncoreMetalMachineTemplates: %w", err)
+	}
+
+	path := field.NewPath("spec", "serverSelector")
+	var allErrs field.ErrorList
+	for _, machine := range machineList.Items {
+		if err := placement.CheckSelectorsCompatible(ironcoremetalcluster.Spec.ServerSelector, machine.Spec.ServerSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, ironcoremetalcluster.Spec.ServerSelector,
+				fmt.Sprintf("conflicts with the ServerSelector of IroncoreMetalMachine %s: %v", machine.Name, err)))
+		}
+	}
+	for i := range templateList.Items {
+		template := &templateList.Items[i]
+		if name, err := clusterNameOf(ctx, v.Client, template); err != nil {
+			return err
+		} else if name != clusterName {
+			continue
+		}
+		if err := placement.CheckSelectorsCompatible(ironcoremetalcluster.Spec.ServerSelector, template.Spec.Template.Spec.ServerSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, ironcoremetalcluster.Spec.ServerSelector,
+				fmt.Sprintf("conflicts with the ServerSelector of IroncoreMetalMachineTemplate %s: %v", template.Name, err)))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(infrav1alpha1.GroupVersion.WithKind("IroncoreMetalCluster").GroupKind(), ironcoremetalcluster.Name, allErrs)
+}
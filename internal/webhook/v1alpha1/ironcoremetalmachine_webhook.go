@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+// SetupIroncoreMetalMachineWebhookWithManager registers the webhook for IroncoreMetalMachine in the manager.
+func SetupIroncoreMetalMachineWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&infrav1alpha1.IroncoreMetalMachine{}).
+		WithValidator(&IroncoreMetalMachineCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1alpha1-ironcoremetalmachine,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalmachines,verbs=create;update,versions=v1alpha1,name=vironcoremetalmachine-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// IroncoreMetalMachineCustomValidator validates IroncoreMetalMachines against the IroncoreMetalCluster of their cluster.
+type IroncoreMetalMachineCustomValidator struct {
+	Client client.Client
+}
+
+var _ webhook.CustomValidator = &IroncoreMetalMachineCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalMachine.
+func (v *IroncoreMetalMachineCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ironcoremetalmachine, ok := obj.(*infrav1alpha1.IroncoreMetalMachine)
+	if !ok {
+		return nil, fmt.Errorf("expected an IroncoreMetalMachine object but got %T", obj)
+	}
+	return nil, v.validate(ctx, ironcoremetalmachine)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalMachine.
+func (v *IroncoreMetalMachineCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	ironcoremetalmachine, ok := newObj.(*infrav1alpha1.IroncoreMetalMachine)
+	if !ok {
+		return nil, fmt.Errorf("expected an IroncoreMetalMachine object for the newObj but got %T", newObj)
+	}
+	return nil, v.validate(ctx, ironcoremetalmachine)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalMachine.
+func (v *IroncoreMetalMachineCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects IroncoreMetalMachines whose ServerSelector conflicts with the ServerSelector of the
+// IroncoreMetalCluster of their cluster.
+func (v *IroncoreMetalMachineCustomValidator) validate(ctx context.Context, ironcoremetalmachine *infrav1alpha1.IroncoreMetalMachine) error {
+	clusterName, err := clusterNameOf(ctx, v.Client, ironcoremetalmachine)
+	if err != nil {
+		return err
+	}
+	allErrs, err := clusterSelectorConflicts(ctx, v.Client, ironcoremetalmachine.Namespace, clusterName,
+		field.NewPath("spec", "serverSelector"), ironcoremetalmachine.Spec.ServerSelector)
+	if err != nil || len(allErrs) == 0 {
+		return err
+	}
+	return apierrors.NewInvalid(infrav1alpha1.GroupVersion.WithKind("IroncoreMetalMachine").GroupKind(), ironcoremetalmachine.Name, allErrs)
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+var _ = Describe("IroncoreMetalMachine Webhook", func() {
+	var (
+		ctx       context.Context
+		validator *IroncoreMetalMachineCustomValidator
+		machine   *infrav1alpha1.IroncoreMetalMachine
+		scheme    *runtime.Scheme
+		cluster   *infrav1alpha1.IroncoreMetalCluster
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(infrav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		cluster = &infrav1alpha1.IroncoreMetalCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tenant-a",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "tenant-a"},
+			},
+			Spec: infrav1alpha1.IroncoreMetalClusterSpec{
+				ServerSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			},
+		}
+		validator = &IroncoreMetalMachineCustomValidator{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+				Spec:       clusterv1.MachineSpec{ClusterName: "tenant-a"},
+			}).Build(),
+		}
+		machine = &infrav1alpha1.IroncoreMetalMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "machine",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "tenant-a"},
+			},
+			Spec: infrav1alpha1.IroncoreMetalMachineSpec{
+				Image:          "ghcr.io/ironcore-dev/os-images/gardenlinux:1443.3",
+				ServerSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+			},
+		}
+	})
+
+	It("should admit machines whose selector is compatible with the cluster", func() {
+		_, err := validator.ValidateCreate(ctx, machine)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject machines selecting the Servers of another tenant", func() {
+		machine.Spec.ServerSelector.MatchLabels["tenant"] = "b"
+		_, err := validator.ValidateCreate(ctx, machine)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+		_, err = validator.ValidateUpdate(ctx, machine.DeepCopy(), machine)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should resolve the cluster of unlabeled machines from their owning Machine", func() {
+		machine.Spec.ServerSelector.MatchLabels["tenant"] = "b"
+		delete(machine.Labels, clusterv1.ClusterNameLabel)
+		machine.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+			Name:       "machine",
+		}}
+		_, err := validator.ValidateCreate(ctx, machine)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should admit machines which do not belong to a cluster yet", func() {
+		machine.Spec.ServerSelector.MatchLabels["tenant"] = "b"
+		delete(machine.Labels, clusterv1.ClusterNameLabel)
+		_, err := validator.ValidateCreate(ctx, machine)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject templates selecting the Servers of another tenant", func() {
+		templateValidator := &IroncoreMetalMachineTemplateCustomValidator{Client: validator.Client}
+		template := &infrav1alpha1.IroncoreMetalMachineTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "template",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       "tenant-a",
+				}},
+			},
+			Spec: infrav1alpha1.IroncoreMetalMachineTemplateSpec{
+				Template: infrav1alpha1.IroncoreMetalMachineTemplateResource{Spec: machine.Spec},
+			},
+		}
+		_, err := templateValidator.ValidateCreate(ctx, template)
+		Expect(err).NotTo(HaveOccurred())
+
+		template.Spec.Template.Spec.ServerSelector.MatchLabels["tenant"] = "b"
+		_, err = templateValidator.ValidateUpdate(ctx, template.DeepCopy(), template)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should reject cluster selector changes conflicting with the machines of the cluster", func() {
+		machine.Spec.ServerSelector.MatchLabels["tenant"] = "a"
+		clusterValidator := &IroncoreMetalClusterCustomValidator{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, machine).Build(),
+		}
+		updated := cluster.DeepCopy()
+		updated.Spec.ServerSelector.MatchLabels["tenant"] = "b"
+		_, err := clusterValidator.ValidateUpdate(ctx, cluster, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+		updated = cluster.DeepCopy()
+		updated.Labels["unrelated"] = "change"
+		_, err = clusterValidator.ValidateUpdate(ctx, cluster, updated)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
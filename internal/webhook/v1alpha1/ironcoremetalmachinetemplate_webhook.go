@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	infrav1alpha1 "github.com/ironcore-dev/cluster-api-provider-ironcore-metal/api/v1alpha1"
+)
+
+// SetupIroncoreMetalMachineTemplateWebhookWithManager registers the webhook for IroncoreMetalMachineTemplate in the manager.
+func SetupIroncoreMetalMachineTemplateWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&infrav1alpha1.IroncoreMetalMachineTemplate{}).
+		WithValidator(&IroncoreMetalMachineTemplateCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1alpha1-ironcoremetalmachinetemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=ironcoremetalmachinetemplates,verbs=create;update,versions=v1alpha1,name=vironcoremetalmachinetemplate-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// IroncoreMetalMachineTemplateCustomValidator validates IroncoreMetalMachineTemplates against the IroncoreMetalCluster
+// of their cluster, so that conflicting selectors are refused before machines are created from the template.
+type IroncoreMetalMachineTemplateCustomValidator struct {
+	Client client.Client
+}
+
+var _ webhook.CustomValidator = &IroncoreMetalMachineTemplateCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalMachineTemplate.
+func (v *IroncoreMetalMachineTemplateCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	template, ok := obj.(*infrav1alpha1.IroncoreMetalMachineTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected an IroncoreMetalMachineTemplate object but got %T", obj)
+	}
+	return nil, v.validate(ctx, template)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalMachineTemplate.
+func (v *IroncoreMetalMachineTemplateCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	template, ok := newObj.(*infrav1alpha1.IroncoreMetalMachineTemplate)
+	if !ok {
+		return nil, fmt.Errorf("expected an IroncoreMetalMachineTemplate object for the newObj but got %T", newObj)
+	}
+	return nil, v.validate(ctx, template)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type IroncoreMetalMachineTemplate.
+func (v *IroncoreMetalMachineTemplateCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects IroncoreMetalMachineTemplates whose ServerSelector conflicts with the ServerSelector of the
+// IroncoreMetalCluster of their cluster.
+func (v *IroncoreMetalMachineTemplateCustomValidator) validate(ctx context.Context, template *infrav1alpha1.IroncoreMetalMachineTemplate) error {
+	clusterName, err := clusterNameOf(ctx, v.Client, template)
+	if err != nil {
+		return err
+	}
+	allErrs, err := clusterSelectorConflicts(ctx, v.Client, template.Namespace, clusterName,
+		field.NewPath("spec", "template", "spec", "serverSelector"), template.Spec.Template.Spec.ServerSelector)
+	if err != nil || len(allErrs) == 0 {
+		return err
+	}
+	return apierrors.NewInvalid(infrav1alpha1.GroupVersion.WithKind("IroncoreMetalMachineTemplate").GroupKind(), template.Name, allErrs)
+}